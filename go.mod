@@ -1,2 +1,10 @@
 module mega
+
 go 1.25.4
+
+require (
+	golang.org/x/term v0.45.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.47.0 // indirect
@@ -0,0 +1,309 @@
+package config
+
+import (
+	"log/slog"
+	"maps"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// Builder constructs a [Config] programmatically, offering a fluent alternative
+	// to loading it from the environment variables for applications that already
+	// have the values in hand.
+	//
+	// The environment variables are not consulted by the builder: the settings left
+	// unset keep their default values, defined by the Default* constants.
+	Builder struct {
+		cfg Config
+	}
+)
+
+// NewBuilder returns a new [Builder] with every setting set to its default value,
+// configured with the given options.
+func NewBuilder(opts ...Option) *Builder {
+	o := newOptions(opts)
+	return &Builder{
+		cfg: Config{
+			logLevel:                DefaultLogLevel,
+			logFormat:               DefaultLogFormat,
+			logOutputs:              []LogOutput{DefaultLogOutput},
+			logSecondaryFormat:      DefaultLogFormat,
+			logFileMaxSizeMB:        DefaultLogFileMaxSizeMB,
+			logFileMaxBackups:       DefaultLogFileMaxBackups,
+			logFileMaxAgeDays:       DefaultLogFileMaxAgeDays,
+			logAddSource:            DefaultLogAddSource,
+			logTimeFormat:           DefaultLogTimeFormat,
+			logTimeUTC:              DefaultLogTimeUTC,
+			logColor:                DefaultLogColor,
+			logSyslogTag:            DefaultLogSyslogTag,
+			logSampleInitial:        DefaultLogSampleInitial,
+			logSampleThereafter:     DefaultLogSampleThereafter,
+			logSampleRate:           DefaultLogSampleRate,
+			serverNetwork:           ServerNetworkTCP,
+			serverAddress:           DefaultServerAddress,
+			serverReadTimeout:       DefaultServerReadTimeout,
+			serverReadHeaderTimeout: DefaultServerReadHeaderTimeout,
+			serverWriteTimeout:      DefaultServerWriteTimeout,
+			serverIdleTimeout:       DefaultServerIdleTimeout,
+			serverRequestTimeout:    DefaultServerRequestTimeout,
+			serverShutdownTimeout:   DefaultServerShutdownTimeout,
+			serverShutdownGrace:     DefaultServerShutdownGrace,
+			serverMaxHeaderBytes:    DefaultServerMaxHeaderBytes,
+			serverMaxConns:          DefaultServerMaxConns,
+			serverAccessLog:         DefaultServerAccessLog,
+			serverHTTP2:             DefaultServerHTTP2,
+			serverKeepAlive:         DefaultServerKeepAlive,
+			serverPprofPathPrefix:   DefaultServerPprofPathPrefix,
+			metricsPath:             DefaultMetricsPath,
+			logOutputFallback:       o.logOutputFallback,
+			logOutputBaseDir:        o.logOutputBaseDir,
+			confinedLogOutput:       o.confinedLogOutput,
+			onShutdown:              o.onShutdown,
+			redactedKeys:            slices.Clone(o.redactedKeys),
+			maxDurations:            maps.Clone(o.maxDurations),
+			resolvedAddress:         new(atomic.Pointer[string]),
+		},
+	}
+}
+
+// SetLogLevel sets the log level.
+func (b *Builder) SetLogLevel(level LogLevel) *Builder {
+	b.cfg.logLevel = level
+	return b
+}
+
+// SetLogLevelsByName sets the log levels of the loggers named by their "logger"
+// attribute, overriding the log level for them.
+func (b *Builder) SetLogLevelsByName(levels map[string]LogLevel) *Builder {
+	b.cfg.logLevelsByName = maps.Clone(levels)
+	return b
+}
+
+// SetLogFormat sets the log format.
+func (b *Builder) SetLogFormat(format LogFormat) *Builder {
+	b.cfg.logFormat = format
+	return b
+}
+
+// SetLogOutput sets the log outputs, each either a standard stream or a file path.
+func (b *Builder) SetLogOutput(outputs ...LogOutput) *Builder {
+	b.cfg.logOutputs = append([]LogOutput(nil), outputs...)
+	return b
+}
+
+// SetLogSecondaryOutput sets the secondary log outputs, to which log records are
+// also written, encoded with format.
+func (b *Builder) SetLogSecondaryOutput(format LogFormat, outputs ...LogOutput) *Builder {
+	b.cfg.logSecondaryFormat = format
+	b.cfg.logSecondaryOutputs = slices.Clone(outputs)
+	return b
+}
+
+// SetLogFileMaxSizeMB sets the maximum size, in megabytes, of a log file before it
+// is rotated.
+func (b *Builder) SetLogFileMaxSizeMB(size int) *Builder {
+	b.cfg.logFileMaxSizeMB = size
+	return b
+}
+
+// SetLogFileMaxBackups sets the maximum number of rotated log files to retain.
+func (b *Builder) SetLogFileMaxBackups(backups int) *Builder {
+	b.cfg.logFileMaxBackups = backups
+	return b
+}
+
+// SetLogFileMaxAgeDays sets the maximum number of days to retain rotated log files.
+func (b *Builder) SetLogFileMaxAgeDays(days int) *Builder {
+	b.cfg.logFileMaxAgeDays = days
+	return b
+}
+
+// SetLogAddSource sets whether the source code position is added to log records.
+func (b *Builder) SetLogAddSource(addSource bool) *Builder {
+	b.cfg.logAddSource = addSource
+	return b
+}
+
+// SetLogTimeFormat sets the format of the log record timestamps.
+func (b *Builder) SetLogTimeFormat(format string) *Builder {
+	b.cfg.logTimeFormat = format
+	return b
+}
+
+// SetLogTimeUTC sets whether the log record timestamps are converted to UTC.
+func (b *Builder) SetLogTimeUTC(utc bool) *Builder {
+	b.cfg.logTimeUTC = utc
+	return b
+}
+
+// SetLogColor sets when the text log levels are colorized.
+func (b *Builder) SetLogColor(color LogColor) *Builder {
+	b.cfg.logColor = color
+	return b
+}
+
+// SetLogSyslogTag sets the tag of the log records written to [LogOutputSyslog].
+func (b *Builder) SetLogSyslogTag(tag string) *Builder {
+	b.cfg.logSyslogTag = tag
+	return b
+}
+
+// SetLogSampling sets the number of records below [LogLevelWarn] logged per second
+// with the same level and message before sampling them, and the sampling of the
+// records exceeding them, of which every Nth is logged.
+func (b *Builder) SetLogSampling(initial, thereafter int) *Builder {
+	b.cfg.logSampleInitial = initial
+	b.cfg.logSampleThereafter = thereafter
+	return b
+}
+
+// SetLogSampleRate sets the fraction, between 0 and 1, of the records below
+// [LogLevelWarn] logged, picked at random.
+func (b *Builder) SetLogSampleRate(rate float64) *Builder {
+	b.cfg.logSampleRate = rate
+	return b
+}
+
+// SetLogDefaultAttrs sets the attributes added to every log record.
+func (b *Builder) SetLogDefaultAttrs(attrs ...slog.Attr) *Builder {
+	b.cfg.logDefaultAttrs = slices.Clone(attrs)
+	return b
+}
+
+// SetServerAddress sets the server address, either a TCP address in the
+// "host:port" format, a Unix domain socket path prefixed with "unix://", or "auto"
+// for any free port.
+func (b *Builder) SetServerAddress(address string) *Builder {
+	if strings.EqualFold(address, autoAddress) {
+		address = autoAddressResolved
+	}
+	b.cfg.serverNetwork = ServerNetworkTCP
+	if path, ok := strings.CutPrefix(address, unixAddressPrefix); ok {
+		b.cfg.serverNetwork = ServerNetworkUnix
+		address = path
+	}
+	b.cfg.serverAddress = address
+	return b
+}
+
+// SetServerReadTimeout sets the server read timeout.
+func (b *Builder) SetServerReadTimeout(timeout time.Duration) *Builder {
+	b.cfg.serverReadTimeout = timeout
+	return b
+}
+
+// SetServerReadHeaderTimeout sets the server read header timeout.
+func (b *Builder) SetServerReadHeaderTimeout(timeout time.Duration) *Builder {
+	b.cfg.serverReadHeaderTimeout = timeout
+	return b
+}
+
+// SetServerWriteTimeout sets the server write timeout.
+func (b *Builder) SetServerWriteTimeout(timeout time.Duration) *Builder {
+	b.cfg.serverWriteTimeout = timeout
+	return b
+}
+
+// SetServerIdleTimeout sets the server idle timeout.
+func (b *Builder) SetServerIdleTimeout(timeout time.Duration) *Builder {
+	b.cfg.serverIdleTimeout = timeout
+	return b
+}
+
+// SetServerRequestTimeout sets the server request timeout.
+func (b *Builder) SetServerRequestTimeout(timeout time.Duration) *Builder {
+	b.cfg.serverRequestTimeout = timeout
+	return b
+}
+
+// SetServerShutdownTimeout sets the server shutdown timeout.
+func (b *Builder) SetServerShutdownTimeout(timeout time.Duration) *Builder {
+	b.cfg.serverShutdownTimeout = timeout
+	return b
+}
+
+// SetServerShutdownGrace sets the server shutdown grace period.
+func (b *Builder) SetServerShutdownGrace(grace time.Duration) *Builder {
+	b.cfg.serverShutdownGrace = grace
+	return b
+}
+
+// SetServerMaxHeaderBytes sets the maximum size, in bytes, of the request headers
+// read by the server.
+func (b *Builder) SetServerMaxHeaderBytes(size int) *Builder {
+	b.cfg.serverMaxHeaderBytes = size
+	return b
+}
+
+// SetServerMaxConns sets the maximum number of connections the server accepts
+// simultaneously, where 0 means unlimited.
+func (b *Builder) SetServerMaxConns(conns int) *Builder {
+	b.cfg.serverMaxConns = conns
+	return b
+}
+
+// SetServerAccessLog sets whether the server logs every request handled.
+func (b *Builder) SetServerAccessLog(accessLog bool) *Builder {
+	b.cfg.serverAccessLog = accessLog
+	return b
+}
+
+// SetServerHTTP2 sets whether the server serves HTTP/2 over TLS connections.
+func (b *Builder) SetServerHTTP2(http2 bool) *Builder {
+	b.cfg.serverHTTP2 = http2
+	return b
+}
+
+// SetServerKeepAlive sets whether the server keeps connections alive between
+// requests.
+func (b *Builder) SetServerKeepAlive(keepAlive bool) *Builder {
+	b.cfg.serverKeepAlive = keepAlive
+	return b
+}
+
+// SetServerPprof sets whether the runtime profiling data is served, and the URL
+// path prefix under which it is.
+func (b *Builder) SetServerPprof(enabled bool, pathPrefix string) *Builder {
+	b.cfg.serverPprof = enabled
+	b.cfg.serverPprofPathPrefix = pathPrefix
+	return b
+}
+
+// SetServerTrustedProxies sets the prefixes of the addresses of the reverse proxies
+// trusted to forward client information in request headers.
+func (b *Builder) SetServerTrustedProxies(prefixes ...netip.Prefix) *Builder {
+	b.cfg.serverTrustedProxies = slices.Clone(prefixes)
+	return b
+}
+
+// SetServerTLSFiles sets the paths of the TLS certificate and private key files,
+// serving HTTPS when both are set.
+func (b *Builder) SetServerTLSFiles(certFile, keyFile string) *Builder {
+	b.cfg.serverTLSCertFile = certFile
+	b.cfg.serverTLSKeyFile = keyFile
+	return b
+}
+
+// SetMetrics sets the address and URL path of the metrics server, disabling it
+// when address is empty.
+func (b *Builder) SetMetrics(address, path string) *Builder {
+	b.cfg.metricsAddress = address
+	b.cfg.metricsPath = path
+	return b
+}
+
+// Build returns the [Config] built, after checking it with [Config.Validate].
+//
+// The builder may be reused afterwards, as the returned configuration does not
+// share any state with it.
+func (b *Builder) Build() (*Config, error) {
+	cfg := b.cfg.Clone()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,148 @@
+package config_test
+
+import (
+	"testing"
+	"time"
+
+	"mega/internal/config"
+)
+
+func TestBuilderBuild(t *testing.T) {
+	cfg, err := config.NewBuilder().
+		SetLogLevel(config.LogLevelDebug).
+		SetLogFormat(config.LogFormatJSON).
+		SetLogOutput(config.LogOutputStderr, "/var/log/app.log").
+		SetServerAddress("unix:///tmp/app.sock").
+		SetServerReadTimeout(time.Second).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if got := cfg.LogLevel(); got != config.LogLevelDebug {
+		t.Errorf("LogLevel() = %q, want %q", got, config.LogLevelDebug)
+	}
+	if got := cfg.LogFormat(); got != config.LogFormatJSON {
+		t.Errorf("LogFormat() = %q, want %q", got, config.LogFormatJSON)
+	}
+	if got := cfg.LogOutputs(); len(got) != 2 || got[0] != config.LogOutputStderr || got[1] != "/var/log/app.log" {
+		t.Errorf("LogOutputs() = %q, want [stderr /var/log/app.log]", got)
+	}
+	if got := cfg.ServerNetwork(); got != config.ServerNetworkUnix {
+		t.Errorf("ServerNetwork() = %q, want %q", got, config.ServerNetworkUnix)
+	}
+	if got := cfg.ServerAddress(); got != "/tmp/app.sock" {
+		t.Errorf("ServerAddress() = %q, want %q", got, "/tmp/app.sock")
+	}
+	if got := cfg.ServerReadTimeout(); got != time.Second {
+		t.Errorf("ServerReadTimeout() = %s, want %s", got, time.Second)
+	}
+}
+
+func TestBuilderBuildInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func(b *config.Builder)
+		key  string
+	}{
+		{
+			name: "empty log level",
+			set:  func(b *config.Builder) { b.SetLogLevel("") },
+			key:  config.EnvLogLevel,
+		},
+		{
+			name: "invalid log level",
+			set:  func(b *config.Builder) { b.SetLogLevel("verbose") },
+			key:  config.EnvLogLevel,
+		},
+		{
+			name: "empty log format",
+			set:  func(b *config.Builder) { b.SetLogFormat("") },
+			key:  config.EnvLogFormat,
+		},
+		{
+			name: "invalid log format",
+			set:  func(b *config.Builder) { b.SetLogFormat("xml") },
+			key:  config.EnvLogFormat,
+		},
+		{
+			name: "no log output",
+			set:  func(b *config.Builder) { b.SetLogOutput() },
+			key:  config.EnvLogOutput,
+		},
+		{
+			name: "empty log output",
+			set:  func(b *config.Builder) { b.SetLogOutput(config.LogOutputStdout, "") },
+			key:  config.EnvLogOutput,
+		},
+		{
+			name: "empty log secondary format",
+			set:  func(b *config.Builder) { b.SetLogSecondaryOutput("", config.LogOutputStdout) },
+			key:  config.EnvLogSecondaryFormat,
+		},
+		{
+			name: "empty log secondary output",
+			set:  func(b *config.Builder) { b.SetLogSecondaryOutput(config.LogFormatJSON, config.LogOutputStdout, "") },
+			key:  config.EnvLogSecondaryOutput,
+		},
+		{
+			name: "negative log file max size",
+			set:  func(b *config.Builder) { b.SetLogFileMaxSizeMB(-1) },
+			key:  config.EnvLogFileMaxSizeMB,
+		},
+		{
+			name: "empty log color",
+			set:  func(b *config.Builder) { b.SetLogColor("") },
+			key:  config.EnvLogColor,
+		},
+		{
+			name: "invalid log color",
+			set:  func(b *config.Builder) { b.SetLogColor("sometimes") },
+			key:  config.EnvLogColor,
+		},
+		{
+			name: "empty server address",
+			set:  func(b *config.Builder) { b.SetServerAddress("") },
+			key:  config.EnvServerAddress,
+		},
+		{
+			name: "invalid server address",
+			set:  func(b *config.Builder) { b.SetServerAddress("localhost") },
+			key:  config.EnvServerAddress,
+		},
+		{
+			name: "empty server socket path",
+			set:  func(b *config.Builder) { b.SetServerAddress("unix://") },
+			key:  config.EnvServerAddress,
+		},
+		{
+			name: "non-positive server max header bytes",
+			set:  func(b *config.Builder) { b.SetServerMaxHeaderBytes(0) },
+			key:  config.EnvServerMaxHeaderBytes,
+		},
+		{
+			name: "empty server pprof path prefix",
+			set:  func(b *config.Builder) { b.SetServerPprof(true, "") },
+			key:  config.EnvServerPprofPathPrefix,
+		},
+		{
+			name: "relative server pprof path prefix",
+			set:  func(b *config.Builder) { b.SetServerPprof(true, "debug/pprof") },
+			key:  config.EnvServerPprofPathPrefix,
+		},
+		{
+			name: "server TLS cert file without key file",
+			set:  func(b *config.Builder) { b.SetServerTLSFiles("builder_test.go", "") },
+			key:  config.EnvServerTLSKeyFile,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := config.NewBuilder()
+			tt.set(b)
+			cfg, err := b.Build()
+			if !hasFieldError(err, tt.key) {
+				t.Errorf("Build() = %v, %v, want a %s field error", cfg, err, tt.key)
+			}
+		})
+	}
+}
@@ -1,11 +1,37 @@
 // Package config loads and provides the application configuration.
+//
+// The configuration is loaded from environment variables, named by the Env*
+// constants. Following the convention of Docker and Kubernetes secrets, the value
+// of any of them, say LOG_LEVEL, may instead be read from the file whose path is
+// set by the same name suffixed with "_FILE" (e.g., LOG_LEVEL_FILE), used only
+// when the variable itself is unset. A single trailing newline is trimmed from the
+// file contents.
+//
+// The whitespace surrounding the values is ignored (e.g., "  :8080  " is read as
+// ":8080"), while the errors report the values as set.
 package config
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
+	"unicode"
 )
 
 type (
@@ -14,6 +40,10 @@ type (
 )
 
 const (
+	// LogLevelTrace captures highly detailed information, more verbose than
+	// [LogLevelDebug], typically useful for deep debugging.
+	LogLevelTrace LogLevel = "trace"
+
 	// LogLevelDebug captures detailed information, typically useful for development
 	// and debugging.
 	LogLevelDebug LogLevel = "debug"
@@ -29,6 +59,69 @@ const (
 	LogLevelError LogLevel = "error"
 )
 
+// SlogLevel returns the [slog.Level] corresponding to the [LogLevel].
+//
+// [LogLevelTrace] is mapped below [slog.LevelDebug], and unknown levels are mapped
+// to [slog.LevelInfo].
+func (l LogLevel) SlogLevel() slog.Level {
+	switch l {
+	case LogLevelTrace:
+		return slog.Level(-8)
+	case LogLevelDebug:
+		return slog.LevelDebug
+	case LogLevelWarn:
+		return slog.LevelWarn
+	case LogLevelError:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// AllLogLevels returns the recognized log levels, ordered by increasing severity.
+func AllLogLevels() []LogLevel {
+	return slices.Clone(logLevels)
+}
+
+// IsValid returns whether the level is one of the recognized log levels, listed by
+// [AllLogLevels].
+func (l LogLevel) IsValid() bool {
+	return slices.Contains(logLevels, l)
+}
+
+// Severity returns the ordinal of the level, increasing with its severity from 0
+// for [LogLevelTrace] to 4 for [LogLevelError], or -1 if the level is unknown.
+func (l LogLevel) Severity() int {
+	return slices.Index(logLevels, l)
+}
+
+// Enabled returns whether the records at the other level are emitted when l is the
+// configured level, that is whether other is at least as severe as l. It returns
+// false if either level is unknown.
+func (l LogLevel) Enabled(other LogLevel) bool {
+	return l.IsValid() && other.IsValid() && other.Severity() >= l.Severity()
+}
+
+// MarshalText implements [encoding.TextMarshaler], returning the canonical name of
+// the level, or an error if the level is unknown.
+func (l LogLevel) MarshalText() ([]byte, error) {
+	level, ok := parseLogLevel(string(l))
+	if !ok {
+		return nil, fmt.Errorf("invalid log level %q; %s", string(l), hintOneOf(logLevels...))
+	}
+	return []byte(level), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler], accepting the values
+// accepted by [EnvLogLevel].
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	level, ok := parseLogLevel(string(text))
+	if !ok {
+		return fmt.Errorf("invalid log level %q; %s", text, hintOneOf(logLevels...))
+	}
+	*l = level
+	return nil
+}
+
 type (
 	// LogFormat represents the encoding style of log records.
 	LogFormat string
@@ -40,8 +133,43 @@ const (
 
 	// LogFormatJSON renders log records as structured JSON objects.
 	LogFormatJSON LogFormat = "json"
+
+	// LogFormatLogfmt renders log records as logfmt "key=value" pairs.
+	LogFormatLogfmt LogFormat = "logfmt"
 )
 
+// AllLogFormats returns the recognized log formats.
+func AllLogFormats() []LogFormat {
+	return slices.Clone(logFormats)
+}
+
+// IsValid returns whether the format is one of the recognized log formats, listed
+// by [AllLogFormats].
+func (f LogFormat) IsValid() bool {
+	return slices.Contains(logFormats, f)
+}
+
+// MarshalText implements [encoding.TextMarshaler], returning the canonical name of
+// the format, or an error if the format is unknown.
+func (f LogFormat) MarshalText() ([]byte, error) {
+	format, ok := parseEnum(string(f), logFormats)
+	if !ok {
+		return nil, fmt.Errorf("invalid log format %q; %s", string(f), hintOneOf(logFormats...))
+	}
+	return []byte(format), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler], accepting the values
+// accepted by [EnvLogFormat].
+func (f *LogFormat) UnmarshalText(text []byte) error {
+	format, ok := parseEnum(string(text), logFormats)
+	if !ok {
+		return fmt.Errorf("invalid log format %q; %s", text, hintOneOf(logFormats...))
+	}
+	*f = format
+	return nil
+}
+
 type (
 	// LogOutput represents the destination stream of log records.
 	LogOutput string
@@ -53,6 +181,70 @@ const (
 
 	// LogOutputStderr writes log records to the standard error stream (stderr).
 	LogOutputStderr LogOutput = "stderr"
+
+	// LogOutputDiscard discards log records, suppressing logging without disabling
+	// the logger (e.g., in benchmarks and tests).
+	LogOutputDiscard LogOutput = "discard"
+
+	// LogOutputSyslog writes log records to the local syslog daemon, on platforms
+	// supporting it (i.e., not Windows, Plan 9, or WebAssembly). A remote daemon is
+	// written to over UDP instead with a value prefixed by "syslog://" and followed
+	// by its address (e.g., "syslog://logs.example.com:514").
+	LogOutputSyslog LogOutput = "syslog"
+)
+
+// isFile returns whether the output is a file path, rather than one of the
+// recognized streams or a syslog daemon.
+func (o LogOutput) isFile() bool {
+	switch o {
+	case LogOutputStdout, LogOutputStderr, LogOutputDiscard, LogOutputSyslog:
+		return false
+	}
+	return !strings.HasPrefix(string(o), logOutputSyslogPrefix)
+}
+
+// IsValid returns whether the output is non-empty, as any value other than the
+// recognized ones (e.g., [LogOutputStdout]) is a file path.
+func (o LogOutput) IsValid() bool {
+	return o != ""
+}
+
+// MarshalText implements [encoding.TextMarshaler], returning the output trimmed of
+// the surrounding whitespace, or an error if it is empty.
+func (o LogOutput) MarshalText() ([]byte, error) {
+	output, ok := parseLogOutput(string(o))
+	if !ok {
+		return nil, errors.New("invalid log output: empty")
+	}
+	return []byte(output), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler], accepting a single value
+// accepted by [EnvLogOutput].
+func (o *LogOutput) UnmarshalText(text []byte) error {
+	output, ok := parseLogOutput(string(text))
+	if !ok {
+		return fmt.Errorf("invalid log output %q; expected %q, %q, %q, %q, or a file path", text, LogOutputStdout, LogOutputStderr, LogOutputDiscard, LogOutputSyslog)
+	}
+	*o = output
+	return nil
+}
+
+type (
+	// LogColor represents when the levels of log records are colorized.
+	LogColor string
+)
+
+const (
+	// LogColorAuto colorizes the levels of log records only when written to a
+	// terminal.
+	LogColorAuto LogColor = "auto"
+
+	// LogColorAlways always colorizes the levels of log records.
+	LogColorAlways LogColor = "always"
+
+	// LogColorNever never colorizes the levels of log records.
+	LogColorNever LogColor = "never"
 )
 
 const (
@@ -61,21 +253,50 @@ const (
 	//
 	// Expected values:
 	//
+	//  - [LogLevelTrace]
 	//  - [LogLevelDebug]
 	//  - [LogLevelInfo]
 	//  - [LogLevelWarn]
 	//  - [LogLevelError]
 	//
+	// Values are case-insensitive, and the following aliases are also accepted:
+	//
+	//  - "dbg" for [LogLevelDebug]
+	//  - "information" for [LogLevelInfo]
+	//  - "warning" for [LogLevelWarn]
+	//  - "err" for [LogLevelError]
+	//
+	// Numeric values are also accepted, ordered by increasing severity:
+	//
+	//  - "0" for [LogLevelDebug]
+	//  - "1" for [LogLevelInfo]
+	//  - "2" for [LogLevelWarn]
+	//  - "3" for [LogLevelError]
+	//
+	// Deprecated aliases: "LOGLEVEL"
+	//
 	// Default: [DefaultLogLevel]
 	EnvLogLevel = "LOG_LEVEL"
 
+	// EnvLogLevelsByName specifies the environment variable name for configuring
+	// the severity or verbosity of the log records of named loggers, overriding
+	// [EnvLogLevel] for them. The name of a logger is the value of its "logger"
+	// attribute (e.g., slog.With("logger", "db")).
+	//
+	// Expected format: comma-separated list of "name=level" pairs, each level as
+	// accepted by [EnvLogLevel] (e.g., "http=debug,db=warn")
+	//
+	// Default: none
+	EnvLogLevelsByName = "LOG_LEVELS"
+
 	// EnvLogFormat specifies the environment variable name for configuring the
 	// [LogFormat].
 	//
-	// Expected values:
+	// Expected values (case-insensitive):
 	//
 	//  - [LogFormatText]
 	//  - [LogFormatJSON]
+	//  - [LogFormatLogfmt]
 	//
 	// Default: [DefaultLogFormat]
 	EnvLogFormat = "LOG_FORMAT"
@@ -87,23 +308,206 @@ const (
 	//
 	//  - [LogOutputStdout]
 	//  - [LogOutputStderr]
-	//  - A custom string (typically a file path)
+	//  - [LogOutputDiscard]
+	//  - [LogOutputSyslog], or "syslog://host:port" for a remote syslog daemon
+	//  - A custom string (typically a file path), which cannot be any of the above
+	//
+	// Only the exact lowercase keywords above denote streams: any other value is a
+	// file path, even when differing only by case (e.g., "STDOUT") or ending with a
+	// keyword (e.g., "/tmp/stdout" or "./stderr.log").
+	//
+	// Multiple destinations can be configured as a comma-separated list (e.g.,
+	// "stderr,/var/log/app.log"), in which case log records are written to all of
+	// them.
+	//
+	// While unset, the default is used, but set and empty (or blank), it is an error
+	// unless loaded with [WithEmptyOutputFallsBack].
 	//
 	// Default: [DefaultLogOutput]
 	EnvLogOutput = "LOG_OUTPUT"
 
+	// EnvLogSecondaryOutput specifies the environment variable name for configuring
+	// the secondary [LogOutput], to which log records are also written, encoded
+	// with [EnvLogSecondaryFormat] (e.g., as JSON to a file for ingestion, while
+	// written as text to stdout).
+	//
+	// Expected values: as [EnvLogOutput]
+	//
+	// Default: none
+	EnvLogSecondaryOutput = "LOG_SECONDARY_OUTPUT"
+
+	// EnvLogSecondaryFormat specifies the environment variable name for configuring
+	// the [LogFormat] of the log records written to [EnvLogSecondaryOutput].
+	//
+	// Expected values: as [EnvLogFormat]
+	//
+	// Default: [DefaultLogFormat]
+	EnvLogSecondaryFormat = "LOG_SECONDARY_FORMAT"
+
+	// EnvLogFileMaxSizeMB specifies the environment variable name for configuring the
+	// maximum size, in megabytes, of a log file before it is rotated.
+	//
+	// Expected format: non-negative integer, where 0 disables rotation (e.g., "100")
+	//
+	// Unlike the other size settings (e.g., [EnvServerMaxHeaderBytes]), it accepts no
+	// unit suffix, as its unit is part of its name.
+	//
+	// Only applies to custom [LogOutput] file paths.
+	//
+	// Default: [DefaultLogFileMaxSizeMB]
+	EnvLogFileMaxSizeMB = "LOG_FILE_MAX_SIZE_MB"
+
+	// EnvLogFileMaxBackups specifies the environment variable name for configuring the
+	// maximum number of rotated log files to retain.
+	//
+	// Expected format: non-negative integer, where 0 retains all (e.g., "5")
+	//
+	// Only applies to custom [LogOutput] file paths.
+	//
+	// Default: [DefaultLogFileMaxBackups]
+	EnvLogFileMaxBackups = "LOG_FILE_MAX_BACKUPS"
+
+	// EnvLogFileMaxAgeDays specifies the environment variable name for configuring the
+	// maximum number of days to retain rotated log files.
+	//
+	// Expected format: non-negative integer, where 0 retains all (e.g., "30")
+	//
+	// Only applies to custom [LogOutput] file paths.
+	//
+	// Default: [DefaultLogFileMaxAgeDays]
+	EnvLogFileMaxAgeDays = "LOG_FILE_MAX_AGE_DAYS"
+
+	// EnvLogAddSource specifies the environment variable name for configuring whether
+	// log records include the source file and line of their caller.
+	//
+	// Expected values (case-insensitive):
+	//
+	//  - "true", "t", "1", "yes", or "on"
+	//  - "false", "f", "0", "no", or "off"
+	//
+	// Default: [DefaultLogAddSource]
+	EnvLogAddSource = "LOG_ADD_SOURCE"
+
+	// EnvLogTimeFormat specifies the environment variable name for configuring the
+	// format of the time of log records.
+	//
+	// Expected values:
+	//
+	//  - "unix" for the number of seconds since the Unix epoch
+	//  - "rfc3339" for [time.RFC3339]
+	//  - A [time.Layout] reference layout (e.g., "2006-01-02 15:04:05")
+	//
+	// Default: [DefaultLogTimeFormat] (the format of the [LogFormat])
+	EnvLogTimeFormat = "LOG_TIME_FORMAT"
+
+	// EnvLogTimeUTC specifies the environment variable name for configuring whether
+	// the time of log records is converted to UTC.
+	//
+	// Expected values (case-insensitive):
+	//
+	//  - "true", "t", "1", "yes", or "on"
+	//  - "false", "f", "0", "no", or "off"
+	//
+	// Default: [DefaultLogTimeUTC]
+	EnvLogTimeUTC = "LOG_TIME_UTC"
+
+	// EnvLogColor specifies the environment variable name for configuring the
+	// [LogColor].
+	//
+	// Expected values (case-insensitive):
+	//
+	//  - [LogColorAuto]
+	//  - [LogColorAlways]
+	//  - [LogColorNever]
+	//
+	// Only applies to [LogFormatText].
+	//
+	// Default: [DefaultLogColor]
+	EnvLogColor = "LOG_COLOR"
+
+	// EnvLogSyslogTag specifies the environment variable name for configuring the tag
+	// of the log records written to [LogOutputSyslog].
+	//
+	// Expected format: any string (e.g., "app")
+	//
+	// Default: [DefaultLogSyslogTag]
+	EnvLogSyslogTag = "LOG_SYSLOG_TAG"
+
+	// EnvLogSampleInitial specifies the environment variable name for configuring
+	// the number of records below [LogLevelWarn] logged per second with the same
+	// level and message before sampling them, where 0 disables sampling.
+	//
+	// Expected format: non-negative integer (e.g., "0", "100")
+	//
+	// Default: [DefaultLogSampleInitial]
+	EnvLogSampleInitial = "LOG_SAMPLE_INITIAL"
+
+	// EnvLogSampleThereafter specifies the environment variable name for configuring
+	// the sampling of the records exceeding [EnvLogSampleInitial] in a second, of
+	// which every Nth is logged, where 0 drops all of them.
+	//
+	// Expected format: non-negative integer (e.g., "0", "100")
+	//
+	// Default: [DefaultLogSampleThereafter]
+	EnvLogSampleThereafter = "LOG_SAMPLE_THEREAFTER"
+
+	// EnvLogSampleRate specifies the environment variable name for configuring the
+	// fraction of the records below [LogLevelWarn] logged, picked at random, where 1
+	// logs all of them and 0 drops all of them. It applies on top of
+	// [EnvLogSampleInitial], to the records passing it.
+	//
+	// Expected format: number between 0 and 1 (e.g., "0.25")
+	//
+	// Default: [DefaultLogSampleRate]
+	EnvLogSampleRate = "LOG_SAMPLE_RATE"
+
+	// EnvLogDefaultAttrs specifies the environment variable name for configuring the
+	// attributes added to every log record, typically identifying the service.
+	//
+	// Expected format: comma-separated list of "key=value" pairs (e.g.,
+	// "service=api,version=1.2.0,env=prod")
+	//
+	// Default: none
+	EnvLogDefaultAttrs = "LOG_DEFAULT_ATTRS"
+
 	// EnvServerAddress specifies the environment variable name for configuring the
 	// server's address.
 	//
-	// Expected format: "<host>:port" (e.g., "localhost:8080", ":3000")
+	// Expected format: "<host>:port" (e.g., "localhost:8080", ":3000"),
+	// "unix://<path>" for a Unix domain socket (e.g., "unix:///tmp/app.sock"), or
+	// "auto" for any free port, as ":0", see [Config.ResolvedAddress]
+	//
+	// Takes precedence over [EnvServerHost] and [EnvServerPort], which are ignored
+	// and reported when set along with it.
 	//
 	// Default: [DefaultServerAddress]
 	EnvServerAddress = "SERVER_ADDRESS"
 
+	// EnvServerHost specifies the environment variable name for configuring the
+	// server's host, joined with [EnvServerPort] into the server's address when
+	// [EnvServerAddress] is unset.
+	//
+	// Expected format: host name or IP address (e.g., "localhost", "0.0.0.0")
+	//
+	// Default: the host of [DefaultServerAddress]
+	EnvServerHost = "SERVER_HOST"
+
+	// EnvServerPort specifies the environment variable name for configuring the
+	// server's port, joined with [EnvServerHost] into the server's address when
+	// [EnvServerAddress] is unset.
+	//
+	// Expected format: integer between [TCPPortMin] and [TCPPortMax] (e.g., "8080")
+	//
+	// Default: the port of [DefaultServerAddress]
+	EnvServerPort = "SERVER_PORT"
+
 	// EnvServerReadTimeout specifies the environment variable name for configuring the
 	// server's read timeout.
 	//
-	// Expected format: [time.Duration] (e.g., "5s", "1m")
+	// Expected format: [time.Duration] (e.g., "5s", "1m"), or an integer in the unit
+	// configured with [WithDefaultDurationUnit], seconds by default (e.g., "30")
+	//
+	// Maximum: [DefaultMaxDuration], unless configured with [WithMaxDuration]
 	//
 	// Default: [DefaultServerReadTimeout]
 	EnvServerReadTimeout = "SERVER_READ_TIMEOUT"
@@ -111,7 +515,10 @@ const (
 	// EnvServerReadHeaderTimeout specifies the environment variable name for
 	// configuring the server's read header timeout.
 	//
-	// Expected format: [time.Duration] (e.g., "5s", "1m")
+	// Expected format: [time.Duration] (e.g., "5s", "1m"), or an integer in the unit
+	// configured with [WithDefaultDurationUnit], seconds by default (e.g., "30")
+	//
+	// Maximum: [DefaultMaxDuration], unless configured with [WithMaxDuration]
 	//
 	// Default: [DefaultServerReadHeaderTimeout]
 	EnvServerReadHeaderTimeout = "SERVER_READ_HEADER_TIMEOUT"
@@ -119,7 +526,10 @@ const (
 	// EnvServerWriteTimeout specifies the environment variable name for configuring
 	// the server's write timeout.
 	//
-	// Expected format: [time.Duration] (e.g., "5s", "1m")
+	// Expected format: [time.Duration] (e.g., "5s", "1m"), or an integer in the unit
+	// configured with [WithDefaultDurationUnit], seconds by default (e.g., "30")
+	//
+	// Maximum: [DefaultMaxDuration], unless configured with [WithMaxDuration]
 	//
 	// Default: [DefaultServerWriteTimeout]
 	EnvServerWriteTimeout = "SERVER_WRITE_TIMEOUT"
@@ -127,18 +537,169 @@ const (
 	// EnvServerIdleTimeout specifies the environment variable name for configuring the
 	// server's idle timeout.
 	//
-	// Expected format: [time.Duration] (e.g., "5s", "1m")
+	// Expected format: [time.Duration] (e.g., "5s", "1m"), or an integer in the unit
+	// configured with [WithDefaultDurationUnit], seconds by default (e.g., "30")
+	//
+	// Maximum: [DefaultMaxDuration], unless configured with [WithMaxDuration]
 	//
 	// Default: [DefaultServerIdleTimeout]
 	EnvServerIdleTimeout = "SERVER_IDLE_TIMEOUT"
 
+	// EnvServerRequestTimeout specifies the environment variable name for configuring
+	// the deadline for handlers to process a request, where 0 means no deadline.
+	//
+	// Expected format: [time.Duration] (e.g., "5s", "1m"), or an integer in the unit
+	// configured with [WithDefaultDurationUnit], seconds by default (e.g., "30")
+	//
+	// Maximum: [DefaultMaxDuration], unless configured with [WithMaxDuration]
+	//
+	// Default: [DefaultServerRequestTimeout]
+	EnvServerRequestTimeout = "SERVER_REQUEST_TIMEOUT"
+
 	// EnvServerShutdownTimeout specifies the environment variable name for configuring
 	// the server's shutdown timeout.
 	//
-	// Expected format: [time.Duration] (e.g., "5s", "1m")
+	// Expected format: [time.Duration] (e.g., "5s", "1m"), or an integer in the unit
+	// configured with [WithDefaultDurationUnit], seconds by default (e.g., "30")
+	//
+	// Maximum: [DefaultMaxDuration], unless configured with [WithMaxDuration]
 	//
 	// Default: [DefaultServerShutdownTimeout]
 	EnvServerShutdownTimeout = "SERVER_SHUTDOWN_TIMEOUT"
+
+	// EnvServerShutdownGrace specifies the environment variable name for configuring
+	// the server's shutdown grace period, during which the server keeps serving
+	// requests once shutting down, for load balancers to stop routing to it.
+	//
+	// Expected format: [time.Duration] (e.g., "5s", "1m"), or an integer in the unit
+	// configured with [WithDefaultDurationUnit], seconds by default (e.g., "30")
+	//
+	// Maximum: [DefaultMaxDuration], unless configured with [WithMaxDuration]
+	//
+	// Default: [DefaultServerShutdownGrace]
+	EnvServerShutdownGrace = "SERVER_SHUTDOWN_GRACE"
+
+	// EnvServerMaxHeaderBytes specifies the environment variable name for configuring
+	// the maximum number of bytes the server reads parsing a request's headers.
+	//
+	// Expected format: positive integer of bytes, optionally suffixed by a decimal
+	// ("KB", "MB", "GB") or binary ("KiB", "MiB", "GiB") unit (e.g., "8192", "64KB",
+	// "1MiB")
+	//
+	// Default: [DefaultServerMaxHeaderBytes]
+	EnvServerMaxHeaderBytes = "SERVER_MAX_HEADER_BYTES"
+
+	// EnvServerMaxConns specifies the environment variable name for configuring the
+	// maximum number of connections the server accepts simultaneously, where 0 means
+	// unlimited.
+	//
+	// Expected format: non-negative integer (e.g., "0", "1000")
+	//
+	// Default: [DefaultServerMaxConns]
+	EnvServerMaxConns = "SERVER_MAX_CONNS"
+
+	// EnvServerAccessLog specifies the environment variable name for configuring
+	// whether the server logs the requests it handles.
+	//
+	// Expected values (case-insensitive):
+	//
+	//  - "true", "t", "1", "yes", or "on"
+	//  - "false", "f", "0", "no", or "off"
+	//
+	// Default: [DefaultServerAccessLog]
+	EnvServerAccessLog = "SERVER_ACCESS_LOG"
+
+	// EnvServerHTTP2 specifies the environment variable name for configuring whether
+	// the server serves HTTP/2 over TLS connections.
+	//
+	// Expected values (case-insensitive):
+	//
+	//  - "true", "t", "1", "yes", or "on"
+	//  - "false", "f", "0", "no", or "off"
+	//
+	// Default: [DefaultServerHTTP2]
+	EnvServerHTTP2 = "SERVER_HTTP2"
+
+	// EnvServerKeepAlive specifies the environment variable name for configuring
+	// whether the server keeps connections alive between requests.
+	//
+	// Expected values (case-insensitive):
+	//
+	//  - "true", "t", "1", "yes", or "on"
+	//  - "false", "f", "0", "no", or "off"
+	//
+	// Default: [DefaultServerKeepAlive]
+	EnvServerKeepAlive = "SERVER_KEEP_ALIVE"
+
+	// EnvServerPprof specifies the environment variable name for configuring whether
+	// the handler of package pprofhandler serves the runtime profiling data of
+	// [net/http/pprof]. The profiles disclose the internals of the application
+	// (e.g., its command line, memory contents, and goroutine stacks) and are costly
+	// to collect, so they must not be exposed publicly: enable them only on demand,
+	// behind authentication or on an address reachable by operators only.
+	//
+	// Expected values (case-insensitive):
+	//
+	//  - "true", "t", "1", "yes", or "on"
+	//  - "false", "f", "0", "no", or "off"
+	//
+	// Default: [DefaultServerPprof]
+	EnvServerPprof = "SERVER_PPROF"
+
+	// EnvServerPprofPathPrefix specifies the environment variable name for
+	// configuring the URL path prefix under which the handler of package
+	// pprofhandler serves the profiling data.
+	//
+	// Expected format: absolute URL path (e.g., "/debug/pprof")
+	//
+	// Default: [DefaultServerPprofPathPrefix]
+	EnvServerPprofPathPrefix = "SERVER_PPROF_PREFIX"
+
+	// EnvServerTrustedProxies specifies the environment variable name for
+	// configuring the addresses of the reverse proxies trusted to forward client
+	// information in request headers (e.g., X-Forwarded-For).
+	//
+	// Expected format: comma-separated list of CIDR prefixes (e.g.,
+	// "10.0.0.0/8,192.168.1.1/32,fd00::/8")
+	//
+	// Default: none (no proxy trusted)
+	EnvServerTrustedProxies = "SERVER_TRUSTED_PROXIES"
+
+	// EnvServerTLSCertFile specifies the environment variable name for configuring the
+	// path of the server's TLS certificate file.
+	//
+	// Expected format: path of an existing PEM-encoded certificate file, set along
+	// with [EnvServerTLSKeyFile] (e.g., "/etc/ssl/certs/app.pem")
+	//
+	// Default: none (TLS disabled)
+	EnvServerTLSCertFile = "SERVER_TLS_CERT_FILE"
+
+	// EnvServerTLSKeyFile specifies the environment variable name for configuring the
+	// path of the server's TLS private key file.
+	//
+	// Expected format: path of an existing PEM-encoded private key file, set along
+	// with [EnvServerTLSCertFile] (e.g., "/etc/ssl/private/app.key")
+	//
+	// Default: none (TLS disabled)
+	EnvServerTLSKeyFile = "SERVER_TLS_KEY_FILE"
+
+	// EnvMetricsAddress specifies the environment variable name for configuring the
+	// address of the metrics server (e.g., exposing Prometheus metrics), which must
+	// differ from [EnvServerAddress].
+	//
+	// Expected format: "<host>:port" with a port between [TCPPortMin] and
+	// [TCPPortMax] (e.g., ":9090")
+	//
+	// Default: none (metrics disabled)
+	EnvMetricsAddress = "METRICS_ADDRESS"
+
+	// EnvMetricsPath specifies the environment variable name for configuring the URL
+	// path at which the metrics server exposes the metrics.
+	//
+	// Expected format: absolute URL path (e.g., "/metrics")
+	//
+	// Default: [DefaultMetricsPath]
+	EnvMetricsPath = "METRICS_PATH"
 )
 
 const (
@@ -154,6 +715,54 @@ const (
 	// [EnvLogOutput] is unset.
 	DefaultLogOutput LogOutput = LogOutputStdout
 
+	// DefaultLogFileMaxSizeMB defines the default maximum size, in megabytes, of a log
+	// file, used as the fallback when [EnvLogFileMaxSizeMB] is unset.
+	DefaultLogFileMaxSizeMB = 100
+
+	// DefaultLogFileMaxBackups defines the default maximum number of rotated log files,
+	// used as the fallback when [EnvLogFileMaxBackups] is unset.
+	DefaultLogFileMaxBackups = 5
+
+	// DefaultLogFileMaxAgeDays defines the default maximum age, in days, of rotated log
+	// files, used as the fallback when [EnvLogFileMaxAgeDays] is unset.
+	DefaultLogFileMaxAgeDays = 30
+
+	// DefaultLogAddSource defines whether log records include their source by default,
+	// used as the fallback when [EnvLogAddSource] is unset.
+	DefaultLogAddSource = false
+
+	// DefaultLogTimeFormat defines the default format of the time of log records, used
+	// as the fallback when [EnvLogTimeFormat] is unset, where empty keeps the format
+	// of the [LogFormat].
+	DefaultLogTimeFormat = ""
+
+	// DefaultLogTimeUTC defines whether the time of log records is converted to UTC by
+	// default, used as the fallback when [EnvLogTimeUTC] is unset.
+	DefaultLogTimeUTC = false
+
+	// DefaultLogColor defines the default [LogColor], used as the fallback when
+	// [EnvLogColor] is unset.
+	DefaultLogColor LogColor = LogColorAuto
+
+	// DefaultLogSyslogTag defines the default tag of the log records written to
+	// [LogOutputSyslog], used as the fallback when [EnvLogSyslogTag] is unset, where
+	// empty uses the name of the running program.
+	DefaultLogSyslogTag = ""
+
+	// DefaultLogSampleInitial defines the default number of records logged per
+	// second with the same level and message before sampling them, used as the
+	// fallback when [EnvLogSampleInitial] is unset.
+	DefaultLogSampleInitial = 0
+
+	// DefaultLogSampleThereafter defines the default sampling of the records
+	// exceeding the initial ones in a second, used as the fallback when
+	// [EnvLogSampleThereafter] is unset.
+	DefaultLogSampleThereafter = 0
+
+	// DefaultLogSampleRate defines the default fraction of the records below
+	// [LogLevelWarn] logged, used as the fallback when [EnvLogSampleRate] is unset.
+	DefaultLogSampleRate = 1.0
+
 	// DefaultServerAddress defines the default server address, used as the fallback
 	// when [EnvServerAddress] is unset.
 	DefaultServerAddress = "localhost:8080"
@@ -174,9 +783,110 @@ const (
 	// fallback when [EnvServerIdleTimeout] is unset.
 	DefaultServerIdleTimeout = 1 * time.Minute
 
+	// DefaultServerRequestTimeout defines the default server request timeout, used as
+	// the fallback when [EnvServerRequestTimeout] is unset.
+	DefaultServerRequestTimeout time.Duration = 0
+
 	// DefaultServerShutdownTimeout defines the default server shutdown timeout, used
 	// as the fallback when [EnvServerShutdownTimeout] is unset.
 	DefaultServerShutdownTimeout = 15 * time.Second
+
+	// DefaultServerShutdownGrace defines the default server shutdown grace period,
+	// used as the fallback when [EnvServerShutdownGrace] is unset.
+	DefaultServerShutdownGrace time.Duration = 0
+
+	// DefaultServerMaxHeaderBytes defines the default server maximum header bytes,
+	// used as the fallback when [EnvServerMaxHeaderBytes] is unset.
+	DefaultServerMaxHeaderBytes = http.DefaultMaxHeaderBytes
+
+	// DefaultServerMaxConns defines the default server maximum simultaneous
+	// connections, used as the fallback when [EnvServerMaxConns] is unset.
+	DefaultServerMaxConns = 0
+
+	// DefaultServerAccessLog defines whether the server logs the requests it handles
+	// by default, used as the fallback when [EnvServerAccessLog] is unset.
+	DefaultServerAccessLog = true
+
+	// DefaultServerHTTP2 defines whether the server serves HTTP/2 by default, used as
+	// the fallback when [EnvServerHTTP2] is unset.
+	DefaultServerHTTP2 = true
+
+	// DefaultServerKeepAlive defines whether the server keeps connections alive by
+	// default, used as the fallback when [EnvServerKeepAlive] is unset.
+	DefaultServerKeepAlive = true
+
+	// DefaultServerPprof defines whether the profiling data is served by default,
+	// used as the fallback when [EnvServerPprof] is unset.
+	DefaultServerPprof = false
+
+	// DefaultServerPprofPathPrefix defines the default URL path prefix of the
+	// profiling data, that of [net/http/pprof], used as the fallback when
+	// [EnvServerPprofPathPrefix] is unset.
+	DefaultServerPprofPathPrefix = "/debug/pprof"
+
+	// DefaultMetricsPath defines the default URL path of the metrics, used as the
+	// fallback when [EnvMetricsPath] is unset.
+	DefaultMetricsPath = "/metrics"
+)
+
+const (
+	// SourceDefault labels the settings with their default value in
+	// [Config.Sources].
+	SourceDefault = "default"
+
+	// SourceEnv labels the settings configured by environment variables in
+	// [Config.Sources].
+	SourceEnv = "env"
+
+	// SourceEnvFile labels the settings read from the files set by environment
+	// variables suffixed with "_FILE" in [Config.Sources].
+	SourceEnvFile = "env_file"
+
+	// SourceFile labels the settings read from a configuration file in
+	// [Config.Sources].
+	SourceFile = "file"
+
+	// SourceRemote labels the settings read from the remote source configured with
+	// [WithRemoteSource] in [Config.Sources].
+	SourceRemote = "remote"
+
+	// SourceOverride labels the settings overridden once loaded, as by
+	// [Config.SetLogLevel], in [Config.Sources].
+	SourceOverride = "override"
+)
+
+const (
+	// logOutputSeparator defines the separator of multiple destinations in
+	// [EnvLogOutput].
+	logOutputSeparator = ","
+
+	// logOutputSyslogPrefix defines the prefix of [EnvLogOutput] values denoting a
+	// remote syslog daemon.
+	logOutputSyslogPrefix = string(LogOutputSyslog) + "://"
+
+	// logDefaultAttrsSeparator defines the separator of multiple pairs in
+	// [EnvLogDefaultAttrs].
+	logDefaultAttrsSeparator = ","
+
+	// logDefaultAttrsKeyValueSeparator defines the separator of the key and value of
+	// each pair in [EnvLogDefaultAttrs].
+	logDefaultAttrsKeyValueSeparator = "="
+
+	// logLevelsByNameSeparator defines the separator of multiple pairs in
+	// [EnvLogLevelsByName].
+	logLevelsByNameSeparator = ","
+
+	// logLevelsByNameKeyValueSeparator defines the separator of the name and level
+	// of each pair in [EnvLogLevelsByName].
+	logLevelsByNameKeyValueSeparator = "="
+
+	// serverTrustedProxiesSeparator defines the separator of multiple prefixes in
+	// [EnvServerTrustedProxies].
+	serverTrustedProxiesSeparator = ","
+
+	// fileEnvSuffix defines the suffix of the environment variables holding the path
+	// of a file whose contents are the value of the environment variable they suffix.
+	fileEnvSuffix = "_FILE"
 )
 
 const (
@@ -187,65 +897,353 @@ const (
 	TCPPortMax = 65535
 )
 
+const (
+	// ServerNetworkTCP defines the network of servers listening on a TCP address.
+	ServerNetworkTCP = "tcp"
+
+	// ServerNetworkUnix defines the network of servers listening on a Unix domain
+	// socket.
+	ServerNetworkUnix = "unix"
+)
+
+const (
+	// unixAddressPrefix defines the prefix of [EnvServerAddress] values denoting a
+	// Unix domain socket.
+	unixAddressPrefix = "unix://"
+
+	// autoAddress defines the [EnvServerAddress] value denoting any free port, and
+	// autoAddressResolved the address it resolves to.
+	autoAddress         = "auto"
+	autoAddressResolved = ":0"
+
+	// serverShutdownTotalMax defines the longest total of the server shutdown grace
+	// period and timeout not warned about, matching the time process managers such
+	// as Kubernetes wait by default before killing a stopping process.
+	serverShutdownTotalMax = 30 * time.Second
+
+	// logTimeFormatUnix defines the [EnvLogTimeFormat] value rendering the time of log
+	// records as the number of seconds since the Unix epoch.
+	logTimeFormatUnix = "unix"
+
+	// logTimeFormatRFC3339 defines the [EnvLogTimeFormat] value rendering the time of
+	// log records with [time.RFC3339].
+	logTimeFormatRFC3339 = "rfc3339"
+)
+
 type (
-	// Config represents the immutable application configuration.
+	// Config represents the immutable application configuration, short of the
+	// deliberate post-load overrides of its Set methods (e.g., [Config.SetLogLevel]).
 	Config struct {
 		logLevel                LogLevel
+		logLevelsByName         map[string]LogLevel
 		logFormat               LogFormat
-		logOutput               LogOutput
+		logOutputs              []LogOutput
+		logSecondaryOutputs     []LogOutput
+		logSecondaryFormat      LogFormat
+		logFileMaxSizeMB        int
+		logFileMaxBackups       int
+		logFileMaxAgeDays       int
+		logAddSource            bool
+		logTimeFormat           string
+		logTimeUTC              bool
+		logColor                LogColor
+		logSyslogTag            string
+		logSampleInitial        int
+		logSampleThereafter     int
+		logSampleRate           float64
+		logDefaultAttrs         []slog.Attr
+		serverNetwork           string
 		serverAddress           string
 		serverReadTimeout       time.Duration
 		serverReadHeaderTimeout time.Duration
 		serverWriteTimeout      time.Duration
 		serverIdleTimeout       time.Duration
+		serverRequestTimeout    time.Duration
 		serverShutdownTimeout   time.Duration
+		serverShutdownGrace     time.Duration
+		serverMaxHeaderBytes    int
+		serverMaxConns          int
+		serverAccessLog         bool
+		serverHTTP2             bool
+		serverKeepAlive         bool
+		serverPprof             bool
+		serverPprofPathPrefix   string
+		serverTrustedProxies    []netip.Prefix
+		serverTLSCertFile       string
+		serverTLSKeyFile        string
+		metricsAddress          string
+		metricsPath             string
+		logOutputFallback       LogOutput
+		logOutputBaseDir        string
+		confinedLogOutput       bool
+		onShutdown              func()
+		redactedKeys            []string
+		maxDurations            map[string]time.Duration
+		sourcePath              string
+		sources                 map[string]string
+		warnings                []string
+		resolvedAddress         *atomic.Pointer[string]
 	}
 )
 
 // New creates and returns a new [Config] instance by loading and validating the
-// application configuration from the environment variables.
+// application configuration from the environment variables, customized by the
+// given options.
 //
 // If the configuration cannot be loaded or validated, a single error joining all
-// errors found is returned.
-func New() (*Config, error) {
-	l := newLoader()
-	cfg := &Config{
-		logLevel:                l.logLevel(),
-		logFormat:               l.logFormat(),
-		logOutput:               l.logOutput(),
-		serverAddress:           l.serverAddress(),
-		serverReadTimeout:       l.serverReadTimeout(),
-		serverReadHeaderTimeout: l.serverReadHeaderTimeout(),
-		serverWriteTimeout:      l.serverWriteTimeout(),
-		serverIdleTimeout:       l.serverIdleTimeout(),
-		serverShutdownTimeout:   l.serverShutdownTimeout(),
-	}
-	if err := l.Err(); err != nil {
-		return nil, fmt.Errorf("failed to load configuration: %w", err)
+// errors found is returned. Non-fatal issues do not prevent loading and are
+// reported by [Config.Warnings] instead.
+//
+// New is equivalent to [NewContext] with [context.Background].
+func New(opts ...Option) (*Config, error) {
+	return NewContext(context.Background(), opts...)
+}
+
+// NewContext is like [New] but stops loading the configuration once ctx is done,
+// returning ctx.Err().
+//
+// Loading from the environment variables is not interruptible, so ctx is only
+// checked before it starts, and passed to the remote source configured with
+// [WithRemoteSource], if any.
+func NewContext(ctx context.Context, opts ...Option) (*Config, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
-	return cfg, nil
+	return load(ctx, newOptions(opts), newLoader(os.LookupEnv, osEnvNames, nil))
 }
 
-// LogLevel returns the configured severity or verbosity of log records.
-func (c *Config) LogLevel() LogLevel {
-	return c.logLevel
+// LoadFromMap creates and returns a new [Config] instance like [New], but looking up
+// the environment variables in env, keyed by name, instead of the process
+// environment.
+func LoadFromMap(env map[string]string, opts ...Option) (*Config, error) {
+	return load(context.Background(), newOptions(opts), newLoader(mapLookupEnv(env), mapEnvNames(env), nil))
 }
 
-// LogFormat returns the configured encoding style of log records.
-func (c *Config) LogFormat() LogFormat {
+// Check loads and validates the configuration exactly as [New] does, including
+// from the remote source configured with [WithRemoteSource], if any, returning the
+// error found, if any, and discarding the [Config]. It suits preflight checks,
+// such as a command verifying the configuration before deploying the application.
+//
+// With [WithStrict], the warnings are also returned as errors.
+//
+// Check is equivalent to [CheckContext] with [context.Background].
+func Check(opts ...Option) error {
+	return CheckContext(context.Background(), opts...)
+}
+
+// CheckContext is like [Check] but loads the configuration as [NewContext] does,
+// stopping once ctx is done and returning ctx.Err().
+func CheckContext(ctx context.Context, opts ...Option) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	o := newOptions(opts)
+	l := newLoader(os.LookupEnv, osEnvNames, nil)
+	if _, err := load(ctx, o, l); err != nil {
+		return err
+	}
+	if warns := l.Warnings(); o.strict && len(warns) > 0 {
+		return fmt.Errorf("configuration warnings: %w", errors.Join(warns...))
+	}
+	return nil
+}
+
+// load loads and validates the configuration with the given loader, falling back
+// to the values of the remote source, if any, read with ctx.
+func load(ctx context.Context, o *options, l *loader) (*Config, error) {
+	if err := l.readRemote(ctx, o); err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg := l.config(o)
+	if o.strict {
+		l.unknownEnv()
+	}
+	if err := l.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	for _, warn := range l.Warnings() {
+		cfg.warnings = append(cfg.warnings, warn.Error())
+	}
+	return cfg, nil
+}
+
+// LogLevel returns the configured severity or verbosity of log records.
+func (c *Config) LogLevel() LogLevel {
+	return c.logLevel
+}
+
+// LogLevelsByName returns the configured severity or verbosity of the log records
+// of named loggers, keyed by logger name.
+func (c *Config) LogLevelsByName() map[string]LogLevel {
+	return maps.Clone(c.logLevelsByName)
+}
+
+// LevelFor returns the configured severity or verbosity of the log records of the
+// logger named by name, falling back to [Config.LogLevel] if it is not configured
+// specifically.
+func (c *Config) LevelFor(name string) LogLevel {
+	if level, ok := c.logLevelsByName[name]; ok {
+		return level
+	}
+	return c.logLevel
+}
+
+// LogFormat returns the configured encoding style of log records.
+func (c *Config) LogFormat() LogFormat {
 	return c.logFormat
 }
 
-// LogOutput returns the configured destination stream of log records.
+// LogOutput returns the configured destination stream of log records, as set,
+// without resolving the file paths, see [Config.ResolvedLogOutputPath].
+//
+// When multiple destinations are configured, they are returned as a
+// comma-separated list.
 func (c *Config) LogOutput() LogOutput {
-	return c.logOutput
+	return LogOutput(joinLogOutputs(c.logOutputs))
+}
+
+// LogOutputs returns the configured destination streams of log records.
+func (c *Config) LogOutputs() []LogOutput {
+	return slices.Clone(c.logOutputs)
+}
+
+// ResolvedLogOutputPath returns the path of the file to which log records are
+// written, that of the first file among the destinations of [Config.LogOutput],
+// cleaned and resolved against the directory configured with
+// [WithLogOutputBaseDir] if relative, or an empty string if none is a file.
+func (c *Config) ResolvedLogOutputPath() string {
+	for _, output := range c.logOutputs {
+		if output.isFile() {
+			return string(c.resolveLogOutput(output))
+		}
+	}
+	return ""
+}
+
+// resolveLogOutput returns output, with its file path, if any, cleaned and resolved
+// against the configured base directory if relative.
+func (c *Config) resolveLogOutput(output LogOutput) LogOutput {
+	if !output.isFile() {
+		return output
+	}
+	path := string(output)
+	if c.logOutputBaseDir != "" && !filepath.IsAbs(path) {
+		return LogOutput(filepath.Join(c.logOutputBaseDir, path))
+	}
+	return LogOutput(filepath.Clean(path))
+}
+
+// LogSecondaryOutputs returns the configured secondary destination streams of log
+// records, or nil if there are none.
+func (c *Config) LogSecondaryOutputs() []LogOutput {
+	return slices.Clone(c.logSecondaryOutputs)
+}
+
+// LogSecondaryFormat returns the configured encoding style of the log records
+// written to [Config.LogSecondaryOutputs].
+func (c *Config) LogSecondaryFormat() LogFormat {
+	return c.logSecondaryFormat
+}
+
+// LogFileMaxSizeMB returns the configured maximum size, in megabytes, of a log file
+// before it is rotated.
+func (c *Config) LogFileMaxSizeMB() int {
+	return c.logFileMaxSizeMB
+}
+
+// LogFileMaxBackups returns the configured maximum number of rotated log files to
+// retain.
+func (c *Config) LogFileMaxBackups() int {
+	return c.logFileMaxBackups
+}
+
+// LogFileMaxAgeDays returns the configured maximum number of days to retain rotated
+// log files.
+func (c *Config) LogFileMaxAgeDays() int {
+	return c.logFileMaxAgeDays
+}
+
+// LogAddSource returns whether log records include the source file and line of
+// their caller.
+func (c *Config) LogAddSource() bool {
+	return c.logAddSource
+}
+
+// LogTimeFormat returns the configured format of the time of log records, or empty
+// when the format of the [LogFormat] is kept.
+func (c *Config) LogTimeFormat() string {
+	return c.logTimeFormat
+}
+
+// LogTimeUTC returns whether the time of log records is converted to UTC.
+func (c *Config) LogTimeUTC() bool {
+	return c.logTimeUTC
+}
+
+// LogColor returns the configured mode of colorization of the levels of log
+// records.
+func (c *Config) LogColor() LogColor {
+	return c.logColor
+}
+
+// LogSyslogTag returns the configured tag of the log records written to
+// [LogOutputSyslog].
+func (c *Config) LogSyslogTag() string {
+	return c.logSyslogTag
+}
+
+// LogSampleInitial returns the configured number of records below [LogLevelWarn]
+// logged per second with the same level and message before sampling them, where 0
+// means sampling is disabled.
+func (c *Config) LogSampleInitial() int {
+	return c.logSampleInitial
+}
+
+// LogSampleThereafter returns the configured sampling of the records exceeding
+// [Config.LogSampleInitial] in a second, of which every Nth is logged, where 0
+// means all of them are dropped.
+func (c *Config) LogSampleThereafter() int {
+	return c.logSampleThereafter
+}
+
+// LogSampleRate returns the configured fraction of the records below
+// [LogLevelWarn] logged, picked at random, where 1 means all of them are logged.
+func (c *Config) LogSampleRate() float64 {
+	return c.logSampleRate
+}
+
+// LogDefaultAttrs returns the configured attributes added to every log record.
+func (c *Config) LogDefaultAttrs() []slog.Attr {
+	return slices.Clone(c.logDefaultAttrs)
+}
+
+// ServerNetwork returns the network of the configured server's address, either
+// [ServerNetworkTCP] or [ServerNetworkUnix].
+func (c *Config) ServerNetwork() string {
+	return c.serverNetwork
 }
 
 // ServerAddress returns the configured server's address.
+//
+// For [ServerNetworkUnix], the address is the path of the socket, without the
+// "unix://" prefix, so that it can be passed along with [Config.ServerNetwork] to
+// [net.Listen].
 func (c *Config) ServerAddress() string {
 	return c.serverAddress
 }
 
+// ResolvedAddress returns the address the server listens on, once listening with
+// [Config.Listen] or [Config.Serve], or an empty string before. Unlike
+// [Config.ServerAddress], it carries the actual port when the configured one is
+// ephemeral (e.g., ":0" or "auto"), letting tests learn where a server started on
+// any free port listens.
+func (c *Config) ResolvedAddress() string {
+	if addr := c.resolvedAddress.Load(); addr != nil {
+		return *addr
+	}
+	return ""
+}
+
 // ServerReadTimeout returns the configured server's read timeout.
 func (c *Config) ServerReadTimeout() time.Duration {
 	return c.serverReadTimeout
@@ -266,90 +1264,1346 @@ func (c *Config) ServerIdleTimeout() time.Duration {
 	return c.serverIdleTimeout
 }
 
+// ServerRequestTimeout returns the configured server's request timeout, where 0
+// means no deadline.
+func (c *Config) ServerRequestTimeout() time.Duration {
+	return c.serverRequestTimeout
+}
+
 // ServerShutdownTimeout returns the configured server's shutdown timeout.
 func (c *Config) ServerShutdownTimeout() time.Duration {
 	return c.serverShutdownTimeout
 }
 
+// ServerShutdownGrace returns the configured server's shutdown grace period.
+func (c *Config) ServerShutdownGrace() time.Duration {
+	return c.serverShutdownGrace
+}
+
+// ServerMaxHeaderBytes returns the configured server's maximum header bytes.
+func (c *Config) ServerMaxHeaderBytes() int {
+	return c.serverMaxHeaderBytes
+}
+
+// ServerMaxConns returns the configured server's maximum simultaneous connections,
+// where 0 means unlimited.
+func (c *Config) ServerMaxConns() int {
+	return c.serverMaxConns
+}
+
+// ServerAccessLog returns whether the server logs the requests it handles, see
+// [Config.AccessLogMiddleware].
+func (c *Config) ServerAccessLog() bool {
+	return c.serverAccessLog
+}
+
+// ServerHTTP2 returns whether the server serves HTTP/2 over TLS connections. When
+// disabled, [Config.HTTPServer] sets an empty [http.Server.TLSNextProto], leaving
+// HTTP/1.1 as the only protocol negotiated.
+func (c *Config) ServerHTTP2() bool {
+	return c.serverHTTP2
+}
+
+// ServerKeepAlive returns whether the server keeps connections alive between
+// requests. When disabled, [Config.HTTPServer] disables them with
+// [http.Server.SetKeepAlivesEnabled], closing each connection after its request.
+func (c *Config) ServerKeepAlive() bool {
+	return c.serverKeepAlive
+}
+
+// PprofEnabled returns whether the runtime profiling data is served by the handler
+// of package pprofhandler. See [EnvServerPprof] for the security implications.
+func (c *Config) PprofEnabled() bool {
+	return c.serverPprof
+}
+
+// PprofPathPrefix returns the configured URL path prefix under which the handler of
+// package pprofhandler serves the profiling data.
+func (c *Config) PprofPathPrefix() string {
+	return c.serverPprofPathPrefix
+}
+
+// TrustedProxies returns the configured prefixes of the addresses of the reverse
+// proxies trusted to forward client information in request headers, empty if no
+// proxy is trusted.
+func (c *Config) TrustedProxies() []netip.Prefix {
+	return slices.Clone(c.serverTrustedProxies)
+}
+
+// IsTrustedProxy returns whether addr belongs to any of the configured
+// [Config.TrustedProxies], letting middleware decide whether to honor the client
+// information forwarded by the peer in request headers (e.g., X-Forwarded-For).
+func (c *Config) IsTrustedProxy(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return slices.ContainsFunc(c.serverTrustedProxies, func(prefix netip.Prefix) bool {
+		return prefix.Contains(addr)
+	})
+}
+
+// ServerTLSCertFile returns the configured path of the server's TLS certificate
+// file.
+func (c *Config) ServerTLSCertFile() string {
+	return c.serverTLSCertFile
+}
+
+// ServerTLSKeyFile returns the configured path of the server's TLS private key
+// file.
+func (c *Config) ServerTLSKeyFile() string {
+	return c.serverTLSKeyFile
+}
+
+// TLSEnabled returns whether the server serves TLS connections, which is the case
+// when both the server's TLS certificate and private key files are configured.
+func (c *Config) TLSEnabled() bool {
+	return c.serverTLSCertFile != "" && c.serverTLSKeyFile != ""
+}
+
+// MetricsAddress returns the configured address of the metrics server, or an empty
+// string if metrics are disabled.
+func (c *Config) MetricsAddress() string {
+	return c.metricsAddress
+}
+
+// MetricsPath returns the configured URL path at which the metrics server exposes
+// the metrics.
+func (c *Config) MetricsPath() string {
+	return c.metricsPath
+}
+
+// MetricsEnabled returns whether the metrics server is enabled, which is the case
+// when its address is configured.
+func (c *Config) MetricsEnabled() bool {
+	return c.metricsAddress != ""
+}
+
+// SourcePath returns the path of the configuration file the configuration was
+// loaded from, as by [NewFromFile] or [NewFromStandardPaths], or empty if it was
+// loaded from the environment variables only.
+func (c *Config) SourcePath() string {
+	return c.sourcePath
+}
+
+// Sources returns the source of the value of every setting, keyed by the
+// environment variable configuring it, for debugging why a setting has its value:
+//
+//   - [SourceDefault] for the default value
+//   - [SourceEnv] for the environment variable, or its deprecated aliases
+//   - [SourceEnvFile] for the file set by the environment variable suffixed with
+//     "_FILE"
+//   - [SourceFile] for the configuration file
+//   - [SourceRemote] for the remote source
+//
+// Sources returns nil if the configuration was not loaded, as by [Builder.Build].
+func (c *Config) Sources() map[string]string {
+	return maps.Clone(c.sources)
+}
+
+// Validate checks that every setting of the configuration is valid, applying the
+// same rules as when loading it from the environment variables, including those
+// configured when loading or building it, such as the maximum durations configured
+// with [WithMaxDuration] and the confinement of the log outputs configured with
+// [WithConfinedLogOutput].
+//
+// If the configuration is invalid, a single error joining all errors found is
+// returned.
+func (c *Config) Validate() error {
+	l := newLoader(mapLookupEnv(c.env()), nil, nil)
+	l.config(c.validationOptions())
+	if err := l.Err(); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+	return nil
+}
+
+// validationOptions returns the options of the loading of the configuration that
+// its settings are validated against (e.g., [WithMaxDuration]), as by
+// [Config.Validate].
+func (c *Config) validationOptions() *options {
+	return &options{
+		maxDurations:      c.maxDurations,
+		logOutputBaseDir:  c.logOutputBaseDir,
+		confinedLogOutput: c.confinedLogOutput,
+	}
+}
+
+// Warnings returns the non-fatal issues found while loading the configuration,
+// such as the use of deprecated environment variable names or of settings ignored
+// in favor of others.
+func (c *Config) Warnings() []string {
+	return slices.Clone(c.warnings)
+}
+
+// Clone returns a copy of the [Config] that can be modified without affecting the
+// original.
+//
+// The copy shares no state with the original and can be safely handed to other
+// goroutines.
+//
+// If c is nil, nil is returned.
+func (c *Config) Clone() *Config {
+	if c == nil {
+		return nil
+	}
+	clone := *c
+	clone.logOutputs = slices.Clone(c.logOutputs)
+	clone.logSecondaryOutputs = slices.Clone(c.logSecondaryOutputs)
+	clone.logLevelsByName = maps.Clone(c.logLevelsByName)
+	clone.serverTrustedProxies = slices.Clone(c.serverTrustedProxies)
+	clone.logDefaultAttrs = slices.Clone(c.logDefaultAttrs)
+	clone.sources = maps.Clone(c.sources)
+	clone.redactedKeys = slices.Clone(c.redactedKeys)
+	clone.maxDurations = maps.Clone(c.maxDurations)
+	clone.warnings = slices.Clone(c.warnings)
+	// c may be listening concurrently, so the address it listens on is copied
+	// atomically, into a pointer of the clone's own.
+	clone.resolvedAddress = new(atomic.Pointer[string])
+	clone.resolvedAddress.Store(c.resolvedAddress.Load())
+	return &clone
+}
+
+var (
+	// logLevels lists the recognized log levels, ordered by increasing severity, as
+	// relied on by [LogLevel.Severity].
+	logLevels  = []LogLevel{LogLevelTrace, LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError}
+	logFormats = []LogFormat{LogFormatText, LogFormatJSON, LogFormatLogfmt}
+	logColors  = []LogColor{LogColorAuto, LogColorAlways, LogColorNever}
+
+	logLevelAliases = map[string]LogLevel{
+		"dbg":         LogLevelDebug,
+		"information": LogLevelInfo,
+		"warning":     LogLevelWarn,
+		"err":         LogLevelError,
+		"0":           LogLevelDebug,
+		"1":           LogLevelInfo,
+		"2":           LogLevelWarn,
+		"3":           LogLevelError,
+	}
+
+	// deprecatedEnvAliases maps the environment variable names to their deprecated
+	// aliases, still honored when the canonical name is unset.
+	deprecatedEnvAliases = map[string][]string{
+		EnvLogLevel: {"LOGLEVEL"},
+	}
+
+	boolTrueValues  = []string{"1", "t", "true", "yes", "on"}
+	boolFalseValues = []string{"0", "f", "false", "no", "off"}
+)
+
 type (
+	// fileEnv holds the result of reading the value of an environment variable from
+	// the file set by its fileEnvSuffix counterpart.
+	fileEnv struct {
+		value string
+		ok    bool
+	}
+
 	loader struct {
-		errs []error
+		opts         *options
+		lookupEnv    func(key string) (string, bool)
+		envNames     func() []string
+		file         map[string]string
+		fileExpanded map[string]string
+		remote       map[string]string
+		fileEnvs     map[string]fileEnv
+		sources      map[string]string
+		errs         []error
+		warns        []error
 	}
 )
 
-func newLoader() *loader {
-	return &loader{}
+// newLoader creates and returns a new loader looking up the environment variables
+// with lookupEnv, and falling back to the given values read from a configuration
+// file, keyed by environment variable name, for those that are unset.
+//
+// envNames lists the names of the environment variables set, scanned in strict
+// mode only; it may be nil if the loader is never used in strict mode.
+func newLoader(lookupEnv func(key string) (string, bool), envNames func() []string, file map[string]string) *loader {
+	return &loader{
+		lookupEnv: lookupEnv,
+		envNames:  envNames,
+		file:      file,
+	}
+}
+
+// config loads and validates the configuration, appending the issues found to the
+// loader.
+func (l *loader) config(o *options) *Config {
+	l.opts = o
+	start := time.Now()
+	if o.defaultDurationUnit != 0 && !slices.Contains(durationUnits, o.defaultDurationUnit) {
+		l.appendError(fmt.Errorf(
+			"invalid default duration unit %s: expected one of: %s",
+			o.defaultDurationUnit, joinStrings(durationUnits, ", "),
+		))
+	}
+	cfg := &Config{
+		logLevel:                l.logLevel(),
+		logLevelsByName:         l.logLevelsByName(),
+		logFormat:               l.logFormat(),
+		logOutputs:              l.logOutputs(),
+		logSecondaryOutputs:     l.logSecondaryOutputs(),
+		logSecondaryFormat:      l.logSecondaryFormat(),
+		logFileMaxSizeMB:        l.logFileMaxSizeMB(),
+		logFileMaxBackups:       l.logFileMaxBackups(),
+		logFileMaxAgeDays:       l.logFileMaxAgeDays(),
+		logAddSource:            l.logAddSource(),
+		logTimeFormat:           l.logTimeFormat(),
+		logTimeUTC:              l.logTimeUTC(),
+		logColor:                l.logColor(),
+		logSyslogTag:            l.logSyslogTag(),
+		logSampleInitial:        l.logSampleInitial(),
+		logSampleThereafter:     l.logSampleThereafter(),
+		logSampleRate:           l.logSampleRate(),
+		logDefaultAttrs:         l.logDefaultAttrs(),
+		serverNetwork:           l.serverNetwork(),
+		serverAddress:           l.serverAddress(),
+		serverReadTimeout:       l.serverReadTimeout(),
+		serverReadHeaderTimeout: l.serverReadHeaderTimeout(),
+		serverWriteTimeout:      l.serverWriteTimeout(),
+		serverIdleTimeout:       l.serverIdleTimeout(),
+		serverRequestTimeout:    l.serverRequestTimeout(),
+		serverShutdownTimeout:   l.serverShutdownTimeout(),
+		serverShutdownGrace:     l.serverShutdownGrace(),
+		serverMaxHeaderBytes:    l.serverMaxHeaderBytes(),
+		serverMaxConns:          l.serverMaxConns(),
+		serverAccessLog:         l.serverAccessLog(),
+		serverHTTP2:             l.serverHTTP2(),
+		serverKeepAlive:         l.serverKeepAlive(),
+		serverPprof:             l.serverPprof(),
+		serverPprofPathPrefix:   l.serverPprofPathPrefix(),
+		serverTrustedProxies:    l.serverTrustedProxies(),
+		serverTLSCertFile:       l.serverTLSCertFile(),
+		serverTLSKeyFile:        l.serverTLSKeyFile(),
+		metricsAddress:          l.metricsAddress(),
+		metricsPath:             l.metricsPath(),
+		logOutputFallback:       o.logOutputFallback,
+		logOutputBaseDir:        o.logOutputBaseDir,
+		confinedLogOutput:       o.confinedLogOutput,
+		onShutdown:              o.onShutdown,
+		redactedKeys:            slices.Clone(o.redactedKeys),
+		maxDurations:            maps.Clone(o.maxDurations),
+		resolvedAddress:         new(atomic.Pointer[string]),
+	}
+	cfg.sources = make(map[string]string)
+	for _, spec := range EnvSpecs() {
+		cfg.sources[spec.Name] = SourceDefault
+	}
+	maps.Copy(cfg.sources, l.sources)
+	o.observe(LoadPhaseParseEnv, start)
+	start = time.Now()
+	l.validate(cfg)
+	o.observe(LoadPhaseValidate, start)
+	return cfg
 }
 
 func (l *loader) logLevel() LogLevel {
-	env, ok := os.LookupEnv(EnvLogLevel)
+	env, ok := l.lookup(EnvLogLevel)
 	if !ok {
 		return DefaultLogLevel
 	}
-	switch val := LogLevel(env); val {
-	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+	if val, ok := parseLogLevel(env); ok {
 		return val
 	}
-	l.appendError(fmt.Errorf("invalid log level (%s) got=%q", EnvLogLevel, env))
+	l.appendError(&FieldError{
+		EnvVar: EnvLogLevel,
+		Value:  env,
+		Reason: "invalid log level",
+		Hint:   hintOneOf(logLevels...),
+	})
 	return ""
 }
 
+func (l *loader) logLevelsByName() map[string]LogLevel {
+	env, ok := l.lookup(EnvLogLevelsByName)
+	if !ok || strings.TrimSpace(env) == "" {
+		return nil
+	}
+	levels := make(map[string]LogLevel)
+	for _, pair := range strings.Split(env, logLevelsByNameSeparator) {
+		name, raw, ok := strings.Cut(pair, logLevelsByNameKeyValueSeparator)
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			l.appendError(&FieldError{
+				EnvVar: EnvLogLevelsByName,
+				Value:  pair,
+				Reason: "invalid log levels pair",
+				Hint:   `expected "name=level" pairs, optionally comma-separated`,
+			})
+			continue
+		}
+		level, ok := parseLogLevel(raw)
+		if !ok {
+			l.appendError(&FieldError{
+				EnvVar: EnvLogLevelsByName,
+				Value:  pair,
+				Reason: "invalid log level",
+				Hint:   hintOneOf(logLevels...),
+			})
+			continue
+		}
+		levels[name] = level
+	}
+	return levels
+}
+
 func (l *loader) logFormat() LogFormat {
-	env, ok := os.LookupEnv(EnvLogFormat)
+	return l.logFormatEnv(EnvLogFormat)
+}
+
+func (l *loader) logOutputs() []LogOutput {
+	env, ok := l.lookup(EnvLogOutput)
+	if !ok || l.opts.emptyOutputFallsBack && strings.TrimSpace(env) == "" {
+		return []LogOutput{DefaultLogOutput}
+	}
+	return l.parseLogOutputs(EnvLogOutput, env)
+}
+
+func (l *loader) logSecondaryOutputs() []LogOutput {
+	env, ok := l.lookup(EnvLogSecondaryOutput)
+	if !ok || strings.TrimSpace(env) == "" {
+		return nil
+	}
+	return l.parseLogOutputs(EnvLogSecondaryOutput, env)
+}
+
+func (l *loader) logSecondaryFormat() LogFormat {
+	return l.logFormatEnv(EnvLogSecondaryFormat)
+}
+
+// logFormatEnv returns the [LogFormat] set by the environment variable named by
+// key, or [DefaultLogFormat] if it is unset.
+func (l *loader) logFormatEnv(key string) LogFormat {
+	env, ok := l.lookup(key)
 	if !ok {
 		return DefaultLogFormat
 	}
-	switch val := LogFormat(env); val {
-	case LogFormatText, LogFormatJSON:
+	if val, ok := parseEnum(env, logFormats); ok {
 		return val
 	}
-	l.appendError(fmt.Errorf("invalid log format (%s) got=%q", EnvLogFormat, env))
+	l.appendError(&FieldError{
+		EnvVar: key,
+		Value:  env,
+		Reason: "invalid log format",
+		Hint:   hintOneOf(logFormats...),
+	})
 	return ""
 }
 
-func (l *loader) logOutput() LogOutput {
-	env, ok := os.LookupEnv(EnvLogOutput)
+// parseLogOutputs returns the log outputs listed by env, the value of the
+// environment variable named by key.
+func (l *loader) parseLogOutputs(key, env string) []LogOutput {
+	vals := strings.Split(env, logOutputSeparator)
+	outputs := make([]LogOutput, 0, len(vals))
+	for _, val := range vals {
+		output, ok := parseLogOutput(val)
+		if !ok {
+			l.appendError(&FieldError{
+				EnvVar: key,
+				Value:  env,
+				Reason: "invalid log output",
+				Hint:   `expected "stdout", "stderr", "discard", or a file path, optionally comma-separated`,
+			})
+			return nil
+		}
+		if addr, remote := strings.CutPrefix(string(output), logOutputSyslogPrefix); output == LogOutputSyslog || remote {
+			if !syslogSupported {
+				l.appendError(&FieldError{
+					EnvVar: key,
+					Value:  string(output),
+					Reason: "unsupported log output",
+					Hint:   fmt.Sprintf("syslog is not supported on %s", runtime.GOOS),
+				})
+				return nil
+			}
+			if _, _, err := net.SplitHostPort(addr); remote && err != nil {
+				l.appendError(&FieldError{
+					EnvVar: key,
+					Value:  string(output),
+					Reason: "invalid log output",
+					Hint:   `expected "syslog://host:port" for a remote syslog daemon`,
+					Err:    err,
+				})
+				return nil
+			}
+		}
+		if l.opts.confinedLogOutput && output.isFile() && !logOutputConfined(l.opts.logOutputBaseDir, string(output)) {
+			dir := l.opts.logOutputBaseDir
+			if dir == "" {
+				dir = "the working directory"
+			}
+			l.appendError(&FieldError{
+				EnvVar: key,
+				Value:  string(output),
+				Reason: "log output escapes its base directory",
+				Hint:   fmt.Sprintf("expected a file path within %s", dir),
+			})
+			return nil
+		}
+		outputs = append(outputs, output)
+	}
+	return outputs
+}
+
+// logOutputConfined returns whether the file path of a log output stays within
+// baseDir, or within the working directory if baseDir is empty.
+func logOutputConfined(baseDir, path string) bool {
+	if !filepath.IsAbs(path) {
+		return filepath.IsLocal(path)
+	}
+	if baseDir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(baseDir, path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+func (l *loader) logFileMaxSizeMB() int {
+	return l.nonNegativeInt(EnvLogFileMaxSizeMB, DefaultLogFileMaxSizeMB, "log file max size")
+}
+
+func (l *loader) logFileMaxBackups() int {
+	return l.nonNegativeInt(EnvLogFileMaxBackups, DefaultLogFileMaxBackups, "log file max backups")
+}
+
+func (l *loader) logFileMaxAgeDays() int {
+	return l.nonNegativeInt(EnvLogFileMaxAgeDays, DefaultLogFileMaxAgeDays, "log file max age")
+}
+
+func (l *loader) logAddSource() bool {
+	return l.boolEnv(EnvLogAddSource, DefaultLogAddSource)
+}
+
+func (l *loader) logTimeFormat() string {
+	env, ok := l.lookup(EnvLogTimeFormat)
 	if !ok {
-		return DefaultLogOutput
+		return DefaultLogTimeFormat
 	}
-	if env == "" {
-		l.appendError(fmt.Errorf("invalid log output (%s) got=%q", EnvLogOutput, env))
+	layout := strings.TrimSpace(env)
+	switch layout {
+	case logTimeFormatUnix, logTimeFormatRFC3339:
+		return layout
+	}
+	// A layout without any layout element formats as itself, and any other invalid
+	// layout fails to parse the time it formats.
+	sample := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	formatted := sample.Format(layout)
+	if _, err := time.Parse(layout, formatted); err != nil || formatted == layout {
+		l.appendError(&FieldError{
+			EnvVar: EnvLogTimeFormat,
+			Value:  env,
+			Reason: "invalid log time format",
+			Hint:   fmt.Sprintf(`expected %q, %q, or a Go reference layout (e.g., "2006-01-02 15:04:05")`, logTimeFormatUnix, logTimeFormatRFC3339),
+		})
 		return ""
 	}
-	return LogOutput(env)
+	return layout
 }
 
-func (l *loader) serverAddress() string {
+func (l *loader) logTimeUTC() bool {
+	return l.boolEnv(EnvLogTimeUTC, DefaultLogTimeUTC)
+}
+
+func (l *loader) logColor() LogColor {
+	env, ok := l.lookup(EnvLogColor)
+	if !ok {
+		return DefaultLogColor
+	}
+	if val, ok := parseEnum(env, logColors); ok {
+		return val
+	}
+	l.appendError(&FieldError{
+		EnvVar: EnvLogColor,
+		Value:  env,
+		Reason: "invalid log color",
+		Hint:   hintOneOf(logColors...),
+	})
 	return ""
 }
 
+func (l *loader) logSyslogTag() string {
+	env, ok := l.lookup(EnvLogSyslogTag)
+	if !ok {
+		return DefaultLogSyslogTag
+	}
+	return strings.TrimSpace(env)
+}
+
+func (l *loader) logSampleInitial() int {
+	return l.nonNegativeInt(EnvLogSampleInitial, DefaultLogSampleInitial, "log sample initial")
+}
+
+func (l *loader) logSampleThereafter() int {
+	return l.nonNegativeInt(EnvLogSampleThereafter, DefaultLogSampleThereafter, "log sample thereafter")
+}
+
+func (l *loader) logSampleRate() float64 {
+	env, ok := l.lookup(EnvLogSampleRate)
+	if !ok {
+		return DefaultLogSampleRate
+	}
+	val, err := strconv.ParseFloat(strings.TrimSpace(env), 64)
+	if err != nil || !(val >= 0 && val <= 1) {
+		l.appendError(&FieldError{
+			EnvVar: EnvLogSampleRate,
+			Value:  env,
+			Reason: "invalid log sample rate",
+			Hint:   `expected a number between 0 and 1 (e.g., "0.25")`,
+		})
+		return 0
+	}
+	return val
+}
+
+func (l *loader) logDefaultAttrs() []slog.Attr {
+	env, ok := l.lookup(EnvLogDefaultAttrs)
+	if !ok || strings.TrimSpace(env) == "" {
+		return nil
+	}
+	pairs := strings.Split(env, logDefaultAttrsSeparator)
+	attrs := make([]slog.Attr, 0, len(pairs))
+	for _, pair := range pairs {
+		key, val, ok := strings.Cut(pair, logDefaultAttrsKeyValueSeparator)
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			l.appendError(&FieldError{
+				EnvVar: EnvLogDefaultAttrs,
+				Value:  pair,
+				Reason: "invalid log default attribute",
+				Hint:   `expected "key=value" pairs, optionally comma-separated`,
+			})
+			continue
+		}
+		attrs = append(attrs, slog.String(key, strings.TrimSpace(val)))
+	}
+	return attrs
+}
+
+func (l *loader) serverNetwork() string {
+	env, _ := l.lookup(EnvServerAddress)
+	if strings.HasPrefix(strings.TrimSpace(env), unixAddressPrefix) {
+		return ServerNetworkUnix
+	}
+	return ServerNetworkTCP
+}
+
+func (l *loader) serverAddress() string {
+	env, ok := l.lookup(EnvServerAddress)
+	host, hostOK := l.lookup(EnvServerHost)
+	port, portOK := l.lookup(EnvServerPort)
+	if ok {
+		if hostOK || portOK {
+			l.appendWarning(fmt.Errorf(
+				"ignored server host (%s) and port (%s) in favor of server address (%s)",
+				EnvServerHost, EnvServerPort, EnvServerAddress,
+			))
+		}
+		addr := strings.TrimSpace(env)
+		if strings.EqualFold(addr, autoAddress) {
+			return autoAddressResolved
+		}
+		if path, unix := strings.CutPrefix(addr, unixAddressPrefix); unix {
+			if path == "" {
+				l.appendError(&FieldError{
+					EnvVar: EnvServerAddress,
+					Value:  env,
+					Reason: "invalid server address",
+					Hint:   `expected "unix://<path>" with a non-empty path`,
+				})
+				return ""
+			}
+			return path
+		}
+		if _, addrPort, err := net.SplitHostPort(addr); err != nil || !validTCPPort(addrPort) {
+			l.appendError(&FieldError{
+				EnvVar: EnvServerAddress,
+				Value:  env,
+				Reason: "invalid server address",
+				Hint:   fmt.Sprintf(`expected "<host>:port" with a port between %d and %d`, TCPPortMin, TCPPortMax),
+			})
+			return ""
+		}
+		return addr
+	}
+	if !hostOK && !portOK {
+		return DefaultServerAddress
+	}
+	defaultHost, defaultPort, _ := net.SplitHostPort(DefaultServerAddress)
+	if !hostOK {
+		host = defaultHost
+	}
+	if !portOK {
+		port = defaultPort
+	}
+	if !validTCPPort(strings.TrimSpace(port)) {
+		l.appendError(&FieldError{
+			EnvVar: EnvServerPort,
+			Value:  port,
+			Reason: "invalid server port",
+			Hint:   fmt.Sprintf("expected an integer between %d and %d", TCPPortMin, TCPPortMax),
+		})
+		return ""
+	}
+	return net.JoinHostPort(strings.TrimSpace(host), strings.TrimSpace(port))
+}
+
 func (l *loader) serverReadTimeout() time.Duration {
-	return 0
+	return l.nonNegativeDuration(EnvServerReadTimeout, DefaultServerReadTimeout, "server read timeout")
 }
 
 func (l *loader) serverReadHeaderTimeout() time.Duration {
-	return 0
+	return l.nonNegativeDuration(EnvServerReadHeaderTimeout, DefaultServerReadHeaderTimeout, "server read header timeout")
 }
 
 func (l *loader) serverWriteTimeout() time.Duration {
-	return 0
+	return l.nonNegativeDuration(EnvServerWriteTimeout, DefaultServerWriteTimeout, "server write timeout")
 }
 
 func (l *loader) serverIdleTimeout() time.Duration {
-	return 0
+	return l.nonNegativeDuration(EnvServerIdleTimeout, DefaultServerIdleTimeout, "server idle timeout")
+}
+
+func (l *loader) serverRequestTimeout() time.Duration {
+	return l.nonNegativeDuration(EnvServerRequestTimeout, DefaultServerRequestTimeout, "server request timeout")
 }
 
 func (l *loader) serverShutdownTimeout() time.Duration {
-	return 0
+	return l.nonNegativeDuration(EnvServerShutdownTimeout, DefaultServerShutdownTimeout, "server shutdown timeout")
+}
+
+func (l *loader) serverShutdownGrace() time.Duration {
+	return l.nonNegativeDuration(EnvServerShutdownGrace, DefaultServerShutdownGrace, "server shutdown grace")
+}
+
+func (l *loader) serverMaxHeaderBytes() int {
+	env, ok := l.lookup(EnvServerMaxHeaderBytes)
+	if !ok {
+		return DefaultServerMaxHeaderBytes
+	}
+	val, err := parseSize(env)
+	if err != nil || val <= 0 || val > math.MaxInt {
+		l.appendError(&FieldError{
+			EnvVar: EnvServerMaxHeaderBytes,
+			Value:  env,
+			Reason: "invalid server max header bytes",
+			Hint:   `expected a positive number of bytes, optionally with a unit (e.g., "64KB", "1MiB")`,
+			Err:    err,
+		})
+		return 0
+	}
+	return int(val)
+}
+
+func (l *loader) serverMaxConns() int {
+	return l.nonNegativeInt(EnvServerMaxConns, DefaultServerMaxConns, "server max conns")
+}
+
+func (l *loader) serverAccessLog() bool {
+	return l.boolEnv(EnvServerAccessLog, DefaultServerAccessLog)
+}
+
+func (l *loader) serverHTTP2() bool {
+	return l.boolEnv(EnvServerHTTP2, DefaultServerHTTP2)
+}
+
+func (l *loader) serverKeepAlive() bool {
+	return l.boolEnv(EnvServerKeepAlive, DefaultServerKeepAlive)
+}
+
+func (l *loader) serverPprof() bool {
+	return l.boolEnv(EnvServerPprof, DefaultServerPprof)
+}
+
+func (l *loader) serverPprofPathPrefix() string {
+	return l.urlPath(EnvServerPprofPathPrefix, DefaultServerPprofPathPrefix, "server pprof path prefix")
+}
+
+func (l *loader) serverTrustedProxies() []netip.Prefix {
+	env, ok := l.lookup(EnvServerTrustedProxies)
+	if !ok || strings.TrimSpace(env) == "" {
+		return nil
+	}
+	vals := strings.Split(env, serverTrustedProxiesSeparator)
+	prefixes := make([]netip.Prefix, 0, len(vals))
+	for _, val := range vals {
+		prefix, err := netip.ParsePrefix(strings.TrimSpace(val))
+		if err != nil {
+			l.appendError(&FieldError{
+				EnvVar: EnvServerTrustedProxies,
+				Value:  val,
+				Reason: "invalid server trusted proxy",
+				Hint:   `expected a CIDR prefix (e.g., "10.0.0.0/8"), optionally comma-separated`,
+				Err:    err,
+			})
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+func (l *loader) serverTLSCertFile() string {
+	return l.existingFile(EnvServerTLSCertFile, "server TLS cert file")
+}
+
+func (l *loader) serverTLSKeyFile() string {
+	return l.existingFile(EnvServerTLSKeyFile, "server TLS key file")
+}
+
+func (l *loader) metricsAddress() string {
+	env, ok := l.lookup(EnvMetricsAddress)
+	addr := strings.TrimSpace(env)
+	if !ok || addr == "" {
+		return ""
+	}
+	if _, port, err := net.SplitHostPort(addr); err != nil || !validTCPPort(port) {
+		l.appendError(&FieldError{
+			EnvVar: EnvMetricsAddress,
+			Value:  env,
+			Reason: "invalid metrics address",
+			Hint:   fmt.Sprintf(`expected "<host>:port" with a port between %d and %d`, TCPPortMin, TCPPortMax),
+		})
+		return ""
+	}
+	return addr
+}
+
+func (l *loader) metricsPath() string {
+	return l.urlPath(EnvMetricsPath, DefaultMetricsPath, "metrics path")
+}
+
+// validate checks the constraints spanning multiple fields of the loaded cfg, once
+// every field has been loaded successfully, also warning about the combinations
+// that are valid but often indicate a mistake.
+func (l *loader) validate(cfg *Config) {
+	if len(l.errs) > 0 {
+		return
+	}
+	switch {
+	case cfg.serverTLSCertFile != "" && cfg.serverTLSKeyFile == "":
+		l.appendError(&FieldError{
+			EnvVar: EnvServerTLSKeyFile,
+			Reason: "missing server TLS key file",
+			Hint:   fmt.Sprintf("required when %s is set", EnvServerTLSCertFile),
+		})
+	case cfg.serverTLSCertFile == "" && cfg.serverTLSKeyFile != "":
+		l.appendError(&FieldError{
+			EnvVar: EnvServerTLSCertFile,
+			Reason: "missing server TLS cert file",
+			Hint:   fmt.Sprintf("required when %s is set", EnvServerTLSKeyFile),
+		})
+	}
+	if cfg.metricsAddress != "" && cfg.serverNetwork != ServerNetworkUnix && cfg.metricsAddress == cfg.serverAddress {
+		l.appendError(&FieldError{
+			EnvVar: EnvMetricsAddress,
+			Value:  cfg.metricsAddress,
+			Reason: "metrics address collides with server address",
+			Hint:   fmt.Sprintf("expected an address other than that of %s", EnvServerAddress),
+		})
+	}
+	// A zero timeout disables the timeout, so it is never out of order.
+	if cfg.serverWriteTimeout > 0 && cfg.serverWriteTimeout < cfg.serverReadTimeout {
+		l.appendWarning(fmt.Errorf(
+			"server write timeout (%s) of %s is shorter than server read timeout (%s) of %s",
+			EnvServerWriteTimeout, cfg.serverWriteTimeout, EnvServerReadTimeout, cfg.serverReadTimeout,
+		))
+	}
+	if total := cfg.serverShutdownGrace + cfg.serverShutdownTimeout; cfg.serverShutdownGrace > 0 && total > serverShutdownTotalMax {
+		l.appendWarning(fmt.Errorf(
+			"server shutdown grace (%s) of %s and timeout (%s) of %s add up to %s, exceeding the %s typically allowed by process managers",
+			EnvServerShutdownGrace, cfg.serverShutdownGrace, EnvServerShutdownTimeout, cfg.serverShutdownTimeout, total, serverShutdownTotalMax,
+		))
+	}
+	if cfg.serverIdleTimeout > 0 && cfg.serverIdleTimeout < cfg.serverReadTimeout {
+		l.appendWarning(fmt.Errorf(
+			"server idle timeout (%s) of %s is shorter than server read timeout (%s) of %s",
+			EnvServerIdleTimeout, cfg.serverIdleTimeout, EnvServerReadTimeout, cfg.serverReadTimeout,
+		))
+	}
+	if l.opts.bindCheck {
+		l.bindCheck(cfg)
+	}
+	if l.opts.certExpiryCheck && cfg.TLSEnabled() {
+		l.certExpiryCheck(cfg)
+	}
+	for _, validator := range l.opts.validators {
+		if err := validator(cfg); err != nil {
+			l.appendError(err)
+		}
+	}
+}
+
+// bindCheck appends an error if the server cannot listen on its address, as when
+// the port is already in use or cannot be bound without privileges. TCP addresses
+// are checked by listening on them transiently, except those with an ephemeral port
+// (e.g., ":0"), which are always bindable, and Unix domain sockets by checking that
+// their parent directory is writable, as the socket file itself may be stale.
+func (l *loader) bindCheck(cfg *Config) {
+	var err error
+	switch cfg.serverNetwork {
+	case ServerNetworkUnix:
+		var f *os.File
+		f, err = os.CreateTemp(filepath.Dir(cfg.serverAddress), ".bindcheck-*")
+		if err == nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	default:
+		if _, port, _ := net.SplitHostPort(cfg.serverAddress); port == "0" {
+			return
+		}
+		var ln net.Listener
+		ln, err = net.Listen(cfg.serverNetwork, cfg.serverAddress)
+		if err == nil {
+			ln.Close()
+		}
+	}
+	if err != nil {
+		l.appendError(&FieldError{
+			EnvVar: EnvServerAddress,
+			Value:  cfg.ServerAddress(),
+			Reason: "server address is not bindable",
+			Err:    err,
+		})
+	}
+}
+
+// certExpiryCheck appends an error if the server's TLS certificate cannot be loaded
+// or has expired, or a warning if it expires within the minimum remaining validity
+// configured with [WithCertExpiryCheck].
+func (l *loader) certExpiryCheck(cfg *Config) {
+	pair, err := tls.LoadX509KeyPair(cfg.serverTLSCertFile, cfg.serverTLSKeyFile)
+	var cert *x509.Certificate
+	if err == nil {
+		cert, err = x509.ParseCertificate(pair.Certificate[0])
+	}
+	if err != nil {
+		l.appendError(&FieldError{
+			EnvVar: EnvServerTLSCertFile,
+			Value:  cfg.serverTLSCertFile,
+			Reason: "invalid server TLS certificate",
+			Hint:   fmt.Sprintf("expected a PEM-encoded certificate matching the private key of %s", EnvServerTLSKeyFile),
+			Err:    err,
+		})
+		return
+	}
+	switch remaining := time.Until(cert.NotAfter); {
+	case remaining <= 0:
+		l.appendError(&FieldError{
+			EnvVar: EnvServerTLSCertFile,
+			Value:  cfg.serverTLSCertFile,
+			Reason: "expired server TLS certificate",
+			Hint:   fmt.Sprintf("expired on %s", cert.NotAfter.Format(time.RFC3339)),
+		})
+	case remaining < l.opts.certMinRemaining:
+		l.appendWarning(&FieldError{
+			EnvVar: EnvServerTLSCertFile,
+			Value:  cfg.serverTLSCertFile,
+			Reason: "expiring server TLS certificate",
+			Hint:   fmt.Sprintf("expires on %s, in less than %s", cert.NotAfter.Format(time.RFC3339), l.opts.certMinRemaining),
+		})
+	}
+}
+
+// unknownEnv appends an error listing the environment variables set within the
+// configuration namespace, that is sharing the prefix of a known environment
+// variable (e.g., "LOG_"), but not configuring any setting.
+func (l *loader) unknownEnv() {
+	known := make(map[string]bool)
+	var prefixes []string
+	for _, spec := range EnvSpecs() {
+		known[spec.Name] = true
+		known[spec.Name+fileEnvSuffix] = true
+		if prefix, _, ok := strings.Cut(spec.Name, "_"); ok && !slices.Contains(prefixes, prefix+"_") {
+			prefixes = append(prefixes, prefix+"_")
+		}
+	}
+	for _, aliases := range deprecatedEnvAliases {
+		for _, alias := range aliases {
+			known[alias] = true
+		}
+	}
+	var unknown []string
+	for _, name := range l.envNames() {
+		if known[name] || !slices.ContainsFunc(prefixes, func(prefix string) bool {
+			return strings.HasPrefix(name, prefix)
+		}) {
+			continue
+		}
+		unknown = append(unknown, name)
+	}
+	if len(unknown) == 0 {
+		return
+	}
+	slices.Sort(unknown)
+	l.appendError(fmt.Errorf("unknown environment variables: %s", strings.Join(slices.Compact(unknown), ", ")))
+}
+
+func (l *loader) nonNegativeInt(key string, def int, name string) int {
+	env, ok := l.lookup(key)
+	if !ok {
+		return def
+	}
+	val, err := strconv.Atoi(strings.TrimSpace(env))
+	if err != nil || val < 0 {
+		l.appendError(&FieldError{
+			EnvVar: key,
+			Value:  env,
+			Reason: "invalid " + name,
+			Hint:   "expected a non-negative integer",
+		})
+		return 0
+	}
+	return val
+}
+
+func (l *loader) nonNegativeDuration(key string, def time.Duration, name string) time.Duration {
+	env, ok := l.lookup(key)
+	if !ok {
+		return def
+	}
+	val, err := l.parseDuration(strings.TrimSpace(env))
+	if err != nil || val < 0 {
+		l.appendError(&FieldError{
+			EnvVar: key,
+			Value:  env,
+			Reason: "invalid " + name,
+			Hint:   `expected a non-negative duration (e.g., "5s", "1m"), or an integer in the default unit`,
+		})
+		return 0
+	}
+	if limit := l.opts.maxDuration(key); limit > 0 && val > limit {
+		l.appendError(&FieldError{
+			EnvVar: key,
+			Value:  env,
+			Reason: name + " out of range",
+			Hint:   fmt.Sprintf("expected at most %s, see WithMaxDuration", limit),
+		})
+		return 0
+	}
+	return val
+}
+
+// parseDuration returns the duration denoted by s, either a [time.Duration] or an
+// integer in the default duration unit, see [WithDefaultDurationUnit].
+func (l *loader) parseDuration(s string) (time.Duration, error) {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.ParseDuration(s)
+	}
+	unit := l.opts.durationUnit()
+	if n > math.MaxInt64/int64(unit) || n < math.MinInt64/int64(unit) {
+		return 0, fmt.Errorf("duration %q overflows", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+func (l *loader) urlPath(key string, def string, name string) string {
+	env, ok := l.lookup(key)
+	if !ok {
+		return def
+	}
+	path := strings.TrimSpace(env)
+	if !strings.HasPrefix(path, "/") || strings.ContainsFunc(path, unicode.IsSpace) {
+		l.appendError(&FieldError{
+			EnvVar: key,
+			Value:  env,
+			Reason: "invalid " + name,
+			Hint:   fmt.Sprintf("expected an absolute URL path without whitespace (e.g., %q)", def),
+		})
+		return ""
+	}
+	return path
+}
+
+func (l *loader) existingFile(key string, name string) string {
+	env, ok := l.lookup(key)
+	if !ok {
+		return ""
+	}
+	path := strings.TrimSpace(env)
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		err = errors.New("is a directory")
+	}
+	if err != nil {
+		l.appendError(&FieldError{
+			EnvVar: key,
+			Value:  env,
+			Reason: "invalid " + name,
+			Hint:   "expected the path of an existing file",
+			Err:    err,
+		})
+		return ""
+	}
+	return path
+}
+
+func (l *loader) boolEnv(key string, def bool) bool {
+	env, ok := l.lookup(key)
+	if !ok {
+		return def
+	}
+	norm := strings.ToLower(strings.TrimSpace(env))
+	switch {
+	case slices.Contains(boolTrueValues, norm):
+		return true
+	case slices.Contains(boolFalseValues, norm):
+		return false
+	}
+	l.appendError(&FieldError{
+		EnvVar: key,
+		Value:  env,
+		Reason: "invalid boolean",
+		Hint:   hintOneOf(slices.Concat(boolTrueValues, boolFalseValues)...),
+	})
+	return false
+}
+
+// lookup retrieves the value of the environment variable named by the key, falling
+// back to its deprecated aliases, which are reported as warnings, then to the file
+// set by its fileEnvSuffix counterpart, then to the configuration file, and then to
+// the remote source, recording the source of the value found.
+func (l *loader) lookup(key string) (string, bool) {
+	if l.failedFast() {
+		return "", false
+	}
+	env, ok := l.lookupEnv(key)
+	for _, alias := range deprecatedEnvAliases[key] {
+		aliasEnv, aliasOK := l.lookupEnv(alias)
+		if !aliasOK {
+			continue
+		}
+		if ok {
+			l.appendWarning(&FieldError{
+				EnvVar: alias,
+				Value:  aliasEnv,
+				Reason: "ignored deprecated environment variable",
+				Hint:   fmt.Sprintf("superseded by %s, which is also set", key),
+			})
+			continue
+		}
+		l.appendWarning(&FieldError{
+			EnvVar: alias,
+			Value:  aliasEnv,
+			Reason: "deprecated environment variable",
+			Hint:   fmt.Sprintf("use %s instead", key),
+		})
+		env, ok = aliasEnv, true
+	}
+	source := SourceEnv
+	if !ok {
+		env, ok = l.lookupFileEnv(key)
+		source = SourceEnvFile
+	}
+	if !ok {
+		env, ok = l.lookupFile(key)
+		source = SourceFile
+	}
+	if !ok {
+		env, ok = l.remote[key]
+		source = SourceRemote
+	}
+	if ok {
+		if l.sources == nil {
+			l.sources = make(map[string]string)
+		}
+		l.sources[key] = source
+	}
+	return env, ok
+}
+
+// lookupFile retrieves the value of the environment variable named by the key from
+// the configuration file, expanded by expand. Each value is expanded once, as some
+// environment variables are looked up several times.
+func (l *loader) lookupFile(key string) (string, bool) {
+	if env, ok := l.fileExpanded[key]; ok {
+		return env, true
+	}
+	env, ok := l.file[key]
+	if !ok {
+		return "", false
+	}
+	env = l.expand(key, env)
+	if l.fileExpanded == nil {
+		l.fileExpanded = make(map[string]string)
+	}
+	l.fileExpanded[key] = env
+	return env, true
+}
+
+// expand returns val, the value of the environment variable named by key, with the
+// references to environment variables, as "${VAR}" or "$VAR", replaced by their
+// values, and "$$" by "$". The references to unset environment variables are
+// replaced by an empty string and reported.
+func (l *loader) expand(key, val string) string {
+	return os.Expand(val, func(name string) string {
+		if name == "$" {
+			return "$"
+		}
+		env, ok := l.lookupEnv(name)
+		if !ok {
+			l.appendWarning(&FieldError{
+				EnvVar: key,
+				Value:  val,
+				Reason: "undefined variable in configuration file",
+				Hint:   fmt.Sprintf("%s is unset, so it expands to an empty string", name),
+			})
+		}
+		return env
+	})
+}
+
+// readRemote reads the values of the remote source configured with o, if any,
+// looked up as a fallback of the configuration file. If the remote source fails,
+// the error is returned, or appended as a warning if the remote source is
+// optional.
+func (l *loader) readRemote(ctx context.Context, o *options) error {
+	if o.remoteSource == nil {
+		return nil
+	}
+	start := time.Now()
+	values, err := o.remoteSource(ctx)
+	o.observe(LoadPhaseReadRemote, start)
+	if err != nil {
+		err = fmt.Errorf("failed to read remote source: %w", err)
+		if o.remoteOptional {
+			l.appendWarning(err)
+			return nil
+		}
+		return err
+	}
+	l.remote = values
+	return nil
+}
+
+// lookupFileEnv retrieves the value of the environment variable named by the key
+// from the file whose path is set by the key suffixed with fileEnvSuffix. Each
+// file is read once, as some environment variables are looked up several times.
+func (l *loader) lookupFileEnv(key string) (string, bool) {
+	if env, ok := l.fileEnvs[key]; ok {
+		return env.value, env.ok
+	}
+	var env fileEnv
+	if path, ok := l.lookupEnv(key + fileEnvSuffix); ok {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			l.appendError(&FieldError{
+				EnvVar: key + fileEnvSuffix,
+				Value:  path,
+				Reason: "unreadable file",
+				Hint:   fmt.Sprintf("expected the path of a readable file holding the value of %s", key),
+				Err:    err,
+			})
+		} else {
+			value := strings.TrimSuffix(string(data), "\n")
+			env = fileEnv{value: strings.TrimSuffix(value, "\r"), ok: true}
+		}
+	}
+	if l.fileEnvs == nil {
+		l.fileEnvs = make(map[string]fileEnv)
+	}
+	l.fileEnvs[key] = env
+	return env.value, env.ok
 }
 
 func (l *loader) appendError(err error) {
+	if l.failedFast() {
+		return
+	}
 	l.errs = append(l.errs, err)
 }
 
+// failedFast returns whether the loading fails fast, see [WithFailFast], and an
+// error was already found, in which case the remaining steps are skipped.
+func (l *loader) failedFast() bool {
+	return l.opts != nil && l.opts.failFast && len(l.errs) > 0
+}
+
+func (l *loader) appendWarning(err error) {
+	l.warns = append(l.warns, err)
+}
+
 func (l *loader) Err() error {
 	if len(l.errs) == 0 {
 		return nil
 	}
 	return errors.Join(l.errs...)
 }
+
+func (l *loader) Warnings() []error {
+	return l.warns
+}
+
+// osEnvNames returns the names of the environment variables of the process.
+func osEnvNames() []string {
+	environ := os.Environ()
+	names := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		names = append(names, name)
+	}
+	return names
+}
+
+// mapEnvNames returns a function listing the names of the environment variables in
+// env.
+func mapEnvNames(env map[string]string) func() []string {
+	return func() []string {
+		return slices.Collect(maps.Keys(env))
+	}
+}
+
+// parseLogLevel returns the [LogLevel] named by raw, or by one of its aliases, and
+// whether any matched.
+func parseLogLevel(raw string) (LogLevel, bool) {
+	if val, ok := parseEnum(raw, logLevels); ok {
+		return val, true
+	}
+	val, ok := logLevelAliases[normalizeEnum(raw)]
+	return val, ok
+}
+
+// joinLogOutputs returns outputs as a comma-separated list.
+func joinLogOutputs(outputs []LogOutput) string {
+	return strings.Join(enumStrings(outputs), logOutputSeparator)
+}
+
+// parseLogOutput returns the [LogOutput] denoted by raw, ignoring the surrounding
+// whitespace, and whether raw is non-empty. Only the exact lowercase keywords
+// denote streams, so that any other value, such as "STDOUT" or "/tmp/stdout", is a
+// file path kept as is.
+func parseLogOutput(raw string) (LogOutput, bool) {
+	val := LogOutput(strings.TrimSpace(raw))
+	return val, val != ""
+}
+
+// parseEnum returns the value of allowed matching raw, ignoring the surrounding
+// whitespace and the case, and whether any matched.
+func parseEnum[T ~string](raw string, allowed []T) (T, bool) {
+	norm := normalizeEnum(raw)
+	for _, val := range allowed {
+		if string(val) == norm {
+			return val, true
+		}
+	}
+	return "", false
+}
+
+// normalizeEnum returns raw trimmed of the surrounding whitespace and lowercased.
+func normalizeEnum(raw string) string {
+	return strings.ToLower(strings.TrimSpace(raw))
+}
+
+// mapLookupEnv returns a function looking up the environment variables in env,
+// keyed by name.
+func mapLookupEnv(env map[string]string) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		val, ok := env[key]
+		return val, ok
+	}
+}
+
+func validTCPPort(port string) bool {
+	val, err := strconv.Atoi(port)
+	return err == nil && val >= TCPPortMin && val <= TCPPortMax
+}
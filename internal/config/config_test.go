@@ -0,0 +1,930 @@
+package config_test
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"mega/internal/config"
+)
+
+// fieldErrors returns the [config.FieldError] values found in the tree of err.
+func fieldErrors(err error) []*config.FieldError {
+	switch err := err.(type) {
+	case *config.FieldError:
+		return []*config.FieldError{err}
+	case interface{ Unwrap() []error }:
+		var errs []*config.FieldError
+		for _, err := range err.Unwrap() {
+			errs = append(errs, fieldErrors(err)...)
+		}
+		return errs
+	case interface{ Unwrap() error }:
+		return fieldErrors(err.Unwrap())
+	}
+	return nil
+}
+
+// hasFieldError returns whether a [config.FieldError] about envVar is joined into
+// err.
+func hasFieldError(err error, envVar string) bool {
+	for _, fe := range fieldErrors(err) {
+		if fe.EnvVar == envVar {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLoadLogLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    config.LogLevel
+		wantErr bool
+	}{
+		{name: "debug", value: "debug", want: config.LogLevelDebug},
+		{name: "info", value: "info", want: config.LogLevelInfo},
+		{name: "warn", value: "warn", want: config.LogLevelWarn},
+		{name: "error", value: "error", want: config.LogLevelError},
+		{name: "numeric debug", value: "0", want: config.LogLevelDebug},
+		{name: "numeric info", value: "1", want: config.LogLevelInfo},
+		{name: "numeric warn", value: "2", want: config.LogLevelWarn},
+		{name: "numeric error", value: "3", want: config.LogLevelError},
+		{name: "numeric out of range", value: "4", wantErr: true},
+		{name: "negative", value: "-1", wantErr: true},
+		{name: "junk", value: "verbose", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(map[string]string{config.EnvLogLevel: tt.value})
+			if tt.wantErr {
+				if !hasFieldError(err, config.EnvLogLevel) {
+					t.Fatalf("LoadFromMap() error = %v, want a %s field error", err, config.EnvLogLevel)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			if got := cfg.LogLevel(); got != tt.want {
+				t.Errorf("LogLevel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadLogAddSource(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{name: "true", value: "true", want: true},
+		{name: "one", value: "1", want: true},
+		{name: "yes", value: "yes", want: true},
+		{name: "t", value: "t", want: true},
+		{name: "on", value: "on", want: true},
+		{name: "false", value: "false"},
+		{name: "zero", value: "0"},
+		{name: "no", value: "no"},
+		{name: "f", value: "f"},
+		{name: "off", value: "off"},
+		{name: "mixed case true", value: "True", want: true},
+		{name: "upper case yes", value: "YES", want: true},
+		{name: "mixed case false", value: "fAlSe"},
+		{name: "upper case off", value: "OFF"},
+		{name: "surrounding whitespace", value: " yes\t", want: true},
+		{name: "invalid", value: "maybe", wantErr: true},
+		{name: "empty", value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(map[string]string{config.EnvLogAddSource: tt.value})
+			if tt.wantErr {
+				if !hasFieldError(err, config.EnvLogAddSource) {
+					t.Fatalf("LoadFromMap() error = %v, want a %s field error", err, config.EnvLogAddSource)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			if got := cfg.LogAddSource(); got != tt.want {
+				t.Errorf("LogAddSource() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewWarnings(t *testing.T) {
+	tests := []struct {
+		name         string
+		env          map[string]string
+		wantErr      bool
+		wantWarnings int
+	}{
+		{
+			name: "no warnings",
+			env:  map[string]string{config.EnvServerAddress: "localhost:8080"},
+		},
+		{
+			name: "warning",
+			env: map[string]string{
+				config.EnvServerAddress: "localhost:8080",
+				config.EnvServerHost:    "example.com",
+			},
+			wantWarnings: 1,
+		},
+		{
+			name: "warning and error",
+			env: map[string]string{
+				config.EnvServerAddress: "localhost:8080",
+				config.EnvServerHost:    "example.com",
+				config.EnvLogLevel:      "verbose",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, val := range tt.env {
+				t.Setenv(key, val)
+			}
+			cfg, err := config.New()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("New() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if got := cfg.Warnings(); len(got) != tt.wantWarnings {
+				t.Errorf("Warnings() = %q, want %d warnings", got, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestLoadEnums(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		get   func(*config.Config) string
+		want  string
+	}{
+		{
+			name:  "log level with whitespace",
+			key:   config.EnvLogLevel,
+			value: "  warn\t",
+			get:   func(c *config.Config) string { return string(c.LogLevel()) },
+			want:  "warn",
+		},
+		{
+			name:  "log level in mixed case",
+			key:   config.EnvLogLevel,
+			value: "DeBuG",
+			get:   func(c *config.Config) string { return string(c.LogLevel()) },
+			want:  "debug",
+		},
+		{
+			name:  "log format with whitespace",
+			key:   config.EnvLogFormat,
+			value: " json ",
+			get:   func(c *config.Config) string { return string(c.LogFormat()) },
+			want:  "json",
+		},
+		{
+			name:  "log format in mixed case",
+			key:   config.EnvLogFormat,
+			value: " LogFmt",
+			get:   func(c *config.Config) string { return string(c.LogFormat()) },
+			want:  "logfmt",
+		},
+		{
+			name:  "log output with whitespace",
+			key:   config.EnvLogOutput,
+			value: "\tstderr  ",
+			get:   func(c *config.Config) string { return string(c.LogOutput()) },
+			want:  "stderr",
+		},
+		{
+			name:  "log output file path with whitespace",
+			key:   config.EnvLogOutput,
+			value: " /var/log/App.log ",
+			get:   func(c *config.Config) string { return string(c.LogOutput()) },
+			want:  "/var/log/App.log",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(map[string]string{tt.key: tt.value})
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			if got := tt.get(cfg); got != tt.want {
+				t.Errorf("%s=%q loaded as %q, want %q", tt.key, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithStrict(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		strict      bool
+		wantUnknown string
+	}{
+		{
+			name: "misspelled without strict mode",
+			env:  map[string]string{"LOG_LEVL": "debug"},
+		},
+		{
+			name:        "misspelled",
+			env:         map[string]string{"LOG_LEVL": "debug"},
+			strict:      true,
+			wantUnknown: "LOG_LEVL",
+		},
+		{
+			name:        "several misspelled",
+			env:         map[string]string{"SERVER_ADRESS": ":80", "LOG_LEVL": "debug"},
+			strict:      true,
+			wantUnknown: "LOG_LEVL, SERVER_ADRESS",
+		},
+		{
+			name: "known and outside the namespace",
+			env: map[string]string{
+				config.EnvLogLevel: "debug",
+				"HOME":             "/root",
+				"DATABASE_URL":     "postgres://localhost",
+			},
+			strict: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []config.Option
+			if tt.strict {
+				opts = append(opts, config.WithStrict())
+			}
+			_, err := config.LoadFromMap(tt.env, opts...)
+			if tt.wantUnknown == "" {
+				if err != nil {
+					t.Fatalf("LoadFromMap() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "unknown environment variables: "+tt.wantUnknown) {
+				t.Errorf("LoadFromMap() error = %v, want the unknown %s", err, tt.wantUnknown)
+			}
+		})
+	}
+}
+
+func TestLoadTimeoutWarnings(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want []string
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{},
+		},
+		{
+			name: "write timeout shorter than read timeout",
+			env: map[string]string{
+				config.EnvServerReadTimeout:  "10s",
+				config.EnvServerWriteTimeout: "5s",
+			},
+			want: []string{"server write timeout (SERVER_WRITE_TIMEOUT) of 5s is shorter than server read timeout (SERVER_READ_TIMEOUT) of 10s"},
+		},
+		{
+			name: "idle timeout shorter than read timeout",
+			env: map[string]string{
+				config.EnvServerReadTimeout: "10s",
+				config.EnvServerIdleTimeout: "5s",
+			},
+			want: []string{"server idle timeout (SERVER_IDLE_TIMEOUT) of 5s is shorter than server read timeout (SERVER_READ_TIMEOUT) of 10s"},
+		},
+		{
+			name: "both shorter than read timeout",
+			env: map[string]string{
+				config.EnvServerReadTimeout:  "1m",
+				config.EnvServerWriteTimeout: "30s",
+				config.EnvServerIdleTimeout:  "45s",
+			},
+			want: []string{
+				"server write timeout (SERVER_WRITE_TIMEOUT) of 30s is shorter than server read timeout (SERVER_READ_TIMEOUT) of 1m0s",
+				"server idle timeout (SERVER_IDLE_TIMEOUT) of 45s is shorter than server read timeout (SERVER_READ_TIMEOUT) of 1m0s",
+			},
+		},
+		{
+			name: "equal timeouts",
+			env: map[string]string{
+				config.EnvServerReadTimeout:  "10s",
+				config.EnvServerWriteTimeout: "10s",
+				config.EnvServerIdleTimeout:  "10s",
+			},
+		},
+		{
+			name: "disabled timeouts",
+			env: map[string]string{
+				config.EnvServerReadTimeout:  "10s",
+				config.EnvServerWriteTimeout: "0s",
+				config.EnvServerIdleTimeout:  "0s",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(tt.env)
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			if got := cfg.Warnings(); !slices.Equal(got, tt.want) {
+				t.Errorf("Warnings() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFileEnv(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "file",
+			env:  map[string]string{config.EnvServerAddress + "_FILE": write("plain", "0.0.0.0:9000")},
+			want: "0.0.0.0:9000",
+		},
+		{
+			name: "file with trailing newline",
+			env:  map[string]string{config.EnvServerAddress + "_FILE": write("newline", "0.0.0.0:9001\n")},
+			want: "0.0.0.0:9001",
+		},
+		{
+			name: "file with trailing carriage return",
+			env:  map[string]string{config.EnvServerAddress + "_FILE": write("crlf", "0.0.0.0:9002\r\n")},
+			want: "0.0.0.0:9002",
+		},
+		{
+			name: "variable set",
+			env: map[string]string{
+				config.EnvServerAddress:           "0.0.0.0:9003",
+				config.EnvServerAddress + "_FILE": write("ignored", "0.0.0.0:9004"),
+			},
+			want: "0.0.0.0:9003",
+		},
+		{
+			name:    "missing file",
+			env:     map[string]string{config.EnvServerAddress + "_FILE": filepath.Join(dir, "missing")},
+			wantErr: filepath.Join(dir, "missing"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(tt.env)
+			if tt.wantErr != "" {
+				fes := fieldErrors(err)
+				if len(fes) != 1 || fes[0].EnvVar != config.EnvServerAddress+"_FILE" || fes[0].Value != tt.wantErr {
+					t.Fatalf("LoadFromMap() error = %v, want a field error naming %s", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			if got := cfg.ServerAddress(); got != tt.want {
+				t.Errorf("ServerAddress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		opts    []config.Option
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			env:  map[string]string{config.EnvLogLevel: "debug"},
+		},
+		{
+			name:    "invalid",
+			env:     map[string]string{config.EnvLogLevel: "verbose"},
+			wantErr: true,
+		},
+		{
+			name: "warning",
+			env: map[string]string{
+				config.EnvServerAddress: "localhost:8080",
+				config.EnvServerHost:    "example.com",
+			},
+		},
+		{
+			name: "warning with strict",
+			env: map[string]string{
+				config.EnvServerAddress: "localhost:8080",
+				config.EnvServerHost:    "example.com",
+			},
+			opts:    []config.Option{config.WithStrict()},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, val := range tt.env {
+				t.Setenv(key, val)
+			}
+			if err := config.Check(tt.opts...); (err != nil) != tt.wantErr {
+				t.Errorf("Check() error = %v, want an error %t", err, tt.wantErr)
+			}
+			if err := config.CheckContext(context.Background(), tt.opts...); (err != nil) != tt.wantErr {
+				t.Errorf("CheckContext() error = %v, want an error %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := config.CheckContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("CheckContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+// textValue is a value implementing both [encoding.TextMarshaler] and
+// [encoding.TextUnmarshaler].
+type textValue interface {
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+}
+
+func TestTextMarshaling(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   func() textValue
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{name: "log level", value: func() textValue { return new(config.LogLevel) }, text: "warn", want: "warn"},
+		{name: "log level in mixed case", value: func() textValue { return new(config.LogLevel) }, text: " DEBUG ", want: "debug"},
+		{name: "numeric log level", value: func() textValue { return new(config.LogLevel) }, text: "3", want: "error"},
+		{name: "invalid log level", value: func() textValue { return new(config.LogLevel) }, text: "verbose", wantErr: true},
+		{name: "empty log level", value: func() textValue { return new(config.LogLevel) }, text: "", wantErr: true},
+		{name: "log format", value: func() textValue { return new(config.LogFormat) }, text: "json", want: "json"},
+		{name: "log format in mixed case", value: func() textValue { return new(config.LogFormat) }, text: "LogFmt", want: "logfmt"},
+		{name: "invalid log format", value: func() textValue { return new(config.LogFormat) }, text: "xml", wantErr: true},
+		{name: "log output stream", value: func() textValue { return new(config.LogOutput) }, text: "stderr", want: "stderr"},
+		{name: "log output path", value: func() textValue { return new(config.LogOutput) }, text: " /var/log/App.log ", want: "/var/log/App.log"},
+		{name: "empty log output", value: func() textValue { return new(config.LogOutput) }, text: "  ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := tt.value()
+			err := v.UnmarshalText([]byte(tt.text))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("UnmarshalText(%q) error = nil, want an error", tt.text)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("UnmarshalText(%q) error = %v", tt.text, err)
+			}
+			got, err := v.MarshalText()
+			if err != nil {
+				t.Fatalf("MarshalText() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("MarshalText() = %q, want %q", got, tt.want)
+			}
+			again := tt.value()
+			if err := again.UnmarshalText(got); err != nil {
+				t.Fatalf("UnmarshalText(%q) error = %v", got, err)
+			}
+			if got, err := again.MarshalText(); err != nil || string(got) != tt.want {
+				t.Errorf("MarshalText() after a round trip = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextMarshalingInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		value encoding.TextMarshaler
+	}{
+		{name: "log level", value: config.LogLevel("verbose")},
+		{name: "log format", value: config.LogFormat("xml")},
+		{name: "log output", value: config.LogOutput("")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := tt.value.MarshalText(); err == nil {
+				t.Errorf("MarshalText() = %q, want an error", got)
+			}
+		})
+	}
+}
+
+func TestTextMarshalingJSON(t *testing.T) {
+	type settings struct {
+		Level  config.LogLevel  `json:"level"`
+		Format config.LogFormat `json:"format"`
+		Output config.LogOutput `json:"output"`
+	}
+	var s settings
+	if err := json.Unmarshal([]byte(`{"level":"WARN","format":" json","output":"stderr"}`), &s); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	want := settings{Level: config.LogLevelWarn, Format: config.LogFormatJSON, Output: config.LogOutputStderr}
+	if s != want {
+		t.Errorf("Unmarshal() = %+v, want %+v", s, want)
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if want := `{"level":"warn","format":"json","output":"stderr"}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+	if err := json.Unmarshal([]byte(`{"level":"verbose"}`), &s); err == nil {
+		t.Error("Unmarshal() of an invalid level error = nil, want an error")
+	}
+}
+
+func TestConfigSources(t *testing.T) {
+	dir := t.TempDir()
+	addressFile := filepath.Join(dir, "address")
+	if err := os.WriteFile(addressFile, []byte("0.0.0.0:9000\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configFile, []byte(`{"log_format": "json"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		load func() (*config.Config, error)
+		want map[string]string
+	}{
+		{
+			name: "env",
+			load: func() (*config.Config, error) {
+				return config.LoadFromMap(map[string]string{config.EnvLogLevel: "debug"})
+			},
+			want: map[string]string{
+				config.EnvLogLevel:  config.SourceEnv,
+				config.EnvLogFormat: config.SourceDefault,
+			},
+		},
+		{
+			name: "env file",
+			load: func() (*config.Config, error) {
+				return config.LoadFromMap(map[string]string{config.EnvServerAddress + "_FILE": addressFile})
+			},
+			want: map[string]string{
+				config.EnvServerAddress: config.SourceEnvFile,
+				config.EnvLogLevel:      config.SourceDefault,
+			},
+		},
+		{
+			name: "file",
+			load: func() (*config.Config, error) {
+				return config.NewFromFile(configFile)
+			},
+			want: map[string]string{
+				config.EnvLogFormat: config.SourceFile,
+				config.EnvLogLevel:  config.SourceDefault,
+			},
+		},
+		{
+			name: "remote",
+			load: func() (*config.Config, error) {
+				return config.LoadFromMap(nil, remoteSource(map[string]string{config.EnvLogLevel: "warn"}, nil))
+			},
+			want: map[string]string{
+				config.EnvLogLevel:  config.SourceRemote,
+				config.EnvLogFormat: config.SourceDefault,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tt.load()
+			if err != nil {
+				t.Fatalf("load error = %v", err)
+			}
+			sources := cfg.Sources()
+			if len(sources) != len(config.EnvSpecs()) {
+				t.Errorf("len(Sources()) = %d, want one per setting", len(sources))
+			}
+			for key, want := range tt.want {
+				if got := sources[key]; got != want {
+					t.Errorf("Sources()[%s] = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLogLevelEnabled(t *testing.T) {
+	const unknown config.LogLevel = "verbose"
+	levels := []config.LogLevel{
+		config.LogLevelTrace,
+		config.LogLevelDebug,
+		config.LogLevelInfo,
+		config.LogLevelWarn,
+		config.LogLevelError,
+		unknown,
+	}
+	tests := []struct {
+		level        config.LogLevel
+		wantSeverity int
+		wantEnabled  []config.LogLevel
+	}{
+		{
+			level:        config.LogLevelTrace,
+			wantSeverity: 0,
+			wantEnabled:  []config.LogLevel{config.LogLevelTrace, config.LogLevelDebug, config.LogLevelInfo, config.LogLevelWarn, config.LogLevelError},
+		},
+		{
+			level:        config.LogLevelDebug,
+			wantSeverity: 1,
+			wantEnabled:  []config.LogLevel{config.LogLevelDebug, config.LogLevelInfo, config.LogLevelWarn, config.LogLevelError},
+		},
+		{
+			level:        config.LogLevelInfo,
+			wantSeverity: 2,
+			wantEnabled:  []config.LogLevel{config.LogLevelInfo, config.LogLevelWarn, config.LogLevelError},
+		},
+		{
+			level:        config.LogLevelWarn,
+			wantSeverity: 3,
+			wantEnabled:  []config.LogLevel{config.LogLevelWarn, config.LogLevelError},
+		},
+		{
+			level:        config.LogLevelError,
+			wantSeverity: 4,
+			wantEnabled:  []config.LogLevel{config.LogLevelError},
+		},
+		{
+			level:        unknown,
+			wantSeverity: -1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.level), func(t *testing.T) {
+			if got := tt.level.Severity(); got != tt.wantSeverity {
+				t.Errorf("Severity() = %d, want %d", got, tt.wantSeverity)
+			}
+			for _, other := range levels {
+				want := slices.Contains(tt.wantEnabled, other)
+				if got := tt.level.Enabled(other); got != want {
+					t.Errorf("Enabled(%q) = %t, want %t", other, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithFailFast(t *testing.T) {
+	env := map[string]string{
+		config.EnvLogLevel:          "verbose",
+		config.EnvLogFormat:         "xml",
+		config.EnvServerReadTimeout: "soon",
+	}
+	tests := []struct {
+		name       string
+		opts       []config.Option
+		wantErrors int
+	}{
+		{name: "collect all", wantErrors: 3},
+		{name: "fail fast", opts: []config.Option{config.WithFailFast()}, wantErrors: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.LoadFromMap(env, tt.opts...)
+			if err == nil {
+				t.Fatal("LoadFromMap() error = nil, want an error")
+			}
+			if got := fieldErrors(err); len(got) != tt.wantErrors {
+				t.Errorf("LoadFromMap() error = %v, want %d field errors", err, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestLoadTrimsWhitespace(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   string
+		value string
+		get   func(*config.Config) any
+		want  any
+	}{
+		{
+			name:  "server address",
+			key:   config.EnvServerAddress,
+			value: "  :8080  ",
+			get:   func(c *config.Config) any { return c.ServerAddress() },
+			want:  ":8080",
+		},
+		{
+			name:  "server read timeout",
+			key:   config.EnvServerReadTimeout,
+			value: " 3s\t",
+			get:   func(c *config.Config) any { return c.ServerReadTimeout() },
+			want:  3 * time.Second,
+		},
+		{
+			name:  "server read header timeout",
+			key:   config.EnvServerReadHeaderTimeout,
+			value: "\t1s ",
+			get:   func(c *config.Config) any { return c.ServerReadHeaderTimeout() },
+			want:  time.Second,
+		},
+		{
+			name:  "server write timeout",
+			key:   config.EnvServerWriteTimeout,
+			value: "  20s",
+			get:   func(c *config.Config) any { return c.ServerWriteTimeout() },
+			want:  20 * time.Second,
+		},
+		{
+			name:  "server idle timeout",
+			key:   config.EnvServerIdleTimeout,
+			value: "2m  ",
+			get:   func(c *config.Config) any { return c.ServerIdleTimeout() },
+			want:  2 * time.Minute,
+		},
+		{
+			name:  "server request timeout",
+			key:   config.EnvServerRequestTimeout,
+			value: " 500ms ",
+			get:   func(c *config.Config) any { return c.ServerRequestTimeout() },
+			want:  500 * time.Millisecond,
+		},
+		{
+			name:  "server shutdown timeout",
+			key:   config.EnvServerShutdownTimeout,
+			value: "\n30s\n",
+			get:   func(c *config.Config) any { return c.ServerShutdownTimeout() },
+			want:  30 * time.Second,
+		},
+		{
+			name:  "server shutdown grace",
+			key:   config.EnvServerShutdownGrace,
+			value: " 5s ",
+			get:   func(c *config.Config) any { return c.ServerShutdownGrace() },
+			want:  5 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(map[string]string{tt.key: tt.value})
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			if got := tt.get(cfg); got != tt.want {
+				t.Errorf("%s=%q loaded as %v, want %v", tt.key, tt.value, got, tt.want)
+			}
+		})
+		t.Run(tt.name+" whitespace only", func(t *testing.T) {
+			const value = "   "
+			_, err := config.LoadFromMap(map[string]string{tt.key: value})
+			fes := fieldErrors(err)
+			if len(fes) != 1 || fes[0].EnvVar != tt.key || fes[0].Value != value {
+				t.Errorf("LoadFromMap() error = %v, want a %s field error reporting %q", err, tt.key, value)
+			}
+		})
+	}
+}
+
+func TestLoadMetrics(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		wantEnabled bool
+		wantAddress string
+		wantPath    string
+	}{
+		{
+			name:     "disabled",
+			env:      map[string]string{},
+			wantPath: config.DefaultMetricsPath,
+		},
+		{
+			name:     "empty address",
+			env:      map[string]string{config.EnvMetricsAddress: "  "},
+			wantPath: config.DefaultMetricsPath,
+		},
+		{
+			name:        "enabled",
+			env:         map[string]string{config.EnvMetricsAddress: ":9090"},
+			wantEnabled: true,
+			wantAddress: ":9090",
+			wantPath:    config.DefaultMetricsPath,
+		},
+		{
+			name: "custom path",
+			env: map[string]string{
+				config.EnvMetricsAddress: "localhost:9090",
+				config.EnvMetricsPath:    "/internal/metrics",
+			},
+			wantEnabled: true,
+			wantAddress: "localhost:9090",
+			wantPath:    "/internal/metrics",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(tt.env)
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			if got := cfg.MetricsEnabled(); got != tt.wantEnabled {
+				t.Errorf("MetricsEnabled() = %t, want %t", got, tt.wantEnabled)
+			}
+			if got := cfg.MetricsAddress(); got != tt.wantAddress {
+				t.Errorf("MetricsAddress() = %q, want %q", got, tt.wantAddress)
+			}
+			if got := cfg.MetricsPath(); got != tt.wantPath {
+				t.Errorf("MetricsPath() = %q, want %q", got, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestLoadMetricsInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		key        string
+		wantReason string
+	}{
+		{
+			name:       "collides with server address",
+			env:        map[string]string{config.EnvServerAddress: ":8080", config.EnvMetricsAddress: ":8080"},
+			key:        config.EnvMetricsAddress,
+			wantReason: "metrics address collides with server address",
+		},
+		{
+			name:       "missing port",
+			env:        map[string]string{config.EnvMetricsAddress: "localhost"},
+			key:        config.EnvMetricsAddress,
+			wantReason: "invalid metrics address",
+		},
+		{
+			name:       "port out of range",
+			env:        map[string]string{config.EnvMetricsAddress: ":65536"},
+			key:        config.EnvMetricsAddress,
+			wantReason: "invalid metrics address",
+		},
+		{
+			name:       "relative path",
+			env:        map[string]string{config.EnvMetricsAddress: ":9090", config.EnvMetricsPath: "metrics"},
+			key:        config.EnvMetricsPath,
+			wantReason: "invalid metrics path",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.LoadFromMap(tt.env)
+			fes := fieldErrors(err)
+			if len(fes) != 1 || fes[0].EnvVar != tt.key || fes[0].Reason != tt.wantReason {
+				t.Errorf("LoadFromMap() error = %v, want a %s field error %q", err, tt.key, tt.wantReason)
+			}
+		})
+	}
+}
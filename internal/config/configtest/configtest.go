@@ -0,0 +1,104 @@
+// Package configtest builds [config.Config] values for tests without mutating the
+// environment variables.
+package configtest
+
+import (
+	"testing"
+	"time"
+
+	"mega/internal/config"
+)
+
+type (
+	// Builder builds a [config.Config] from the settings given to its chainable
+	// methods, defaulting the others as [config.New] does.
+	Builder struct {
+		env  map[string]string
+		opts []config.Option
+	}
+)
+
+// New creates and returns a new empty [Builder].
+func New() *Builder {
+	return &Builder{
+		env: make(map[string]string),
+	}
+}
+
+// WithEnv sets the value of the environment variable named by the key.
+func (b *Builder) WithEnv(key, value string) *Builder {
+	b.env[key] = value
+	return b
+}
+
+// WithOptions appends options to those used to load the [config.Config].
+func (b *Builder) WithOptions(opts ...config.Option) *Builder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// WithLogLevel sets the [config.LogLevel].
+func (b *Builder) WithLogLevel(level config.LogLevel) *Builder {
+	return b.WithEnv(config.EnvLogLevel, string(level))
+}
+
+// WithLogFormat sets the [config.LogFormat].
+func (b *Builder) WithLogFormat(format config.LogFormat) *Builder {
+	return b.WithEnv(config.EnvLogFormat, string(format))
+}
+
+// WithLogOutput sets the [config.LogOutput].
+func (b *Builder) WithLogOutput(output config.LogOutput) *Builder {
+	return b.WithEnv(config.EnvLogOutput, string(output))
+}
+
+// WithServerAddress sets the server's address.
+func (b *Builder) WithServerAddress(address string) *Builder {
+	return b.WithEnv(config.EnvServerAddress, address)
+}
+
+// WithServerReadTimeout sets the server's read timeout.
+func (b *Builder) WithServerReadTimeout(timeout time.Duration) *Builder {
+	return b.WithEnv(config.EnvServerReadTimeout, timeout.String())
+}
+
+// WithServerReadHeaderTimeout sets the server's read header timeout.
+func (b *Builder) WithServerReadHeaderTimeout(timeout time.Duration) *Builder {
+	return b.WithEnv(config.EnvServerReadHeaderTimeout, timeout.String())
+}
+
+// WithServerWriteTimeout sets the server's write timeout.
+func (b *Builder) WithServerWriteTimeout(timeout time.Duration) *Builder {
+	return b.WithEnv(config.EnvServerWriteTimeout, timeout.String())
+}
+
+// WithServerIdleTimeout sets the server's idle timeout.
+func (b *Builder) WithServerIdleTimeout(timeout time.Duration) *Builder {
+	return b.WithEnv(config.EnvServerIdleTimeout, timeout.String())
+}
+
+// WithServerRequestTimeout sets the server's request timeout.
+func (b *Builder) WithServerRequestTimeout(timeout time.Duration) *Builder {
+	return b.WithEnv(config.EnvServerRequestTimeout, timeout.String())
+}
+
+// WithServerShutdownTimeout sets the server's shutdown timeout.
+func (b *Builder) WithServerShutdownTimeout(timeout time.Duration) *Builder {
+	return b.WithEnv(config.EnvServerShutdownTimeout, timeout.String())
+}
+
+// WithServerShutdownGrace sets the server's shutdown grace period.
+func (b *Builder) WithServerShutdownGrace(grace time.Duration) *Builder {
+	return b.WithEnv(config.EnvServerShutdownGrace, grace.String())
+}
+
+// Build loads and returns the [config.Config] with [config.LoadFromMap], failing
+// tb if the settings are invalid.
+func (b *Builder) Build(tb testing.TB) *config.Config {
+	tb.Helper()
+	cfg, err := config.LoadFromMap(b.env, b.opts...)
+	if err != nil {
+		tb.Fatalf("configtest: %v", err)
+	}
+	return cfg
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"context"
+)
+
+type (
+	// contextKey is the key of the [Config] carried by a [context.Context].
+	contextKey struct{}
+)
+
+// ContextWith returns a copy of ctx carrying c, retrieved with [FromContext], so
+// that the configuration reaches the middleware and handlers deep in the request
+// handling without resorting to global variables.
+//
+// The configuration is shared by every holder of the context, so it should be
+// treated as read-only.
+func ContextWith(ctx context.Context, c *Config) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the [Config] carried by ctx, as stored by [ContextWith], and
+// whether there is one.
+func FromContext(ctx context.Context) (*Config, bool) {
+	c, ok := ctx.Value(contextKey{}).(*Config)
+	return c, ok
+}
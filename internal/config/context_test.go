@@ -0,0 +1,55 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+
+	"mega/internal/config"
+)
+
+func TestFromContext(t *testing.T) {
+	cfg, err := config.LoadFromMap(nil)
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	type otherKey struct{}
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		want   *config.Config
+		wantOK bool
+	}{
+		{
+			name: "absent",
+			ctx:  context.Background(),
+		},
+		{
+			name: "absent with other values",
+			ctx:  context.WithValue(context.Background(), otherKey{}, cfg),
+		},
+		{
+			name:   "present",
+			ctx:    config.ContextWith(context.Background(), cfg),
+			want:   cfg,
+			wantOK: true,
+		},
+		{
+			name: "present in a parent",
+			ctx: func() context.Context {
+				ctx, cancel := context.WithCancel(config.ContextWith(context.Background(), cfg))
+				t.Cleanup(cancel)
+				return context.WithValue(ctx, otherKey{}, "value")
+			}(),
+			want:   cfg,
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := config.FromContext(tt.ctx)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("FromContext() = %p, %t, want %p, %t", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// dotEnvExportPrefix defines the optional prefix of the lines of dotenv files,
+	// as written by shell scripts.
+	dotEnvExportPrefix = "export"
+
+	// dotEnvCommentPrefix defines the prefix of the comments of dotenv files.
+	dotEnvCommentPrefix = "#"
+)
+
+// NewFromDotEnv creates and returns a new [Config] instance like [New], falling
+// back to the values read from the dotenv files at paths for the environment
+// variables that are unset. When several files set the same environment variable,
+// the value of the last one is used.
+//
+// Each line of a dotenv file is either blank, a comment starting with "#", or a
+// "KEY=VALUE" assignment, optionally prefixed with "export", as in:
+//
+//	# Logging
+//	export LOG_LEVEL=debug
+//	LOG_FORMAT=json # inline comment
+//	LOG_SYSLOG_TAG="my app"
+//	LOG_TIME_FORMAT=
+//
+// The values may be enclosed in single or double quotes, kept as written. Unquoted
+// values end before the first "#" preceded by whitespace, and "KEY=" sets an empty
+// value. The values are expanded as those of the configuration files read by
+// [NewFromFile]. The keys not naming any environment variable of the configuration
+// are ignored, as dotenv files are typically shared with other settings.
+func NewFromDotEnv(paths ...string) (*Config, error) {
+	values := make(map[string]string)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration: failed to read dotenv file (%s): %w", path, err)
+		}
+		if err := parseDotEnv(path, data, values); err != nil {
+			return nil, fmt.Errorf("failed to load configuration: invalid dotenv file: %w", err)
+		}
+	}
+	return load(context.Background(), newOptions(nil), newLoader(os.LookupEnv, osEnvNames, values))
+}
+
+// parseDotEnv parses data, the contents of the dotenv file at path, into values,
+// keyed by environment variable name. The errors found are joined, each prefixed
+// with the path, if any, and line number.
+func parseDotEnv(path string, data []byte, values map[string]string) error {
+	var errs []error
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, dotEnvCommentPrefix) {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(line, dotEnvExportPrefix); ok && rest != strings.TrimLeft(rest, " \t") {
+			line = strings.TrimSpace(rest)
+		}
+		key, val, err := parseDotEnvLine(line)
+		if err != nil {
+			if path == "" {
+				errs = append(errs, fmt.Errorf("line %d: %w", i+1, err))
+			} else {
+				errs = append(errs, fmt.Errorf("%s:%d: %w", path, i+1, err))
+			}
+			continue
+		}
+		values[key] = val
+	}
+	return errors.Join(errs...)
+}
+
+// parseDotEnvLine returns the key and value assigned by line, a "KEY=VALUE"
+// assignment.
+func parseDotEnvLine(line string) (string, string, error) {
+	key, val, ok := strings.Cut(line, "=")
+	key = strings.TrimSpace(key)
+	if !ok || !validDotEnvKey(key) {
+		return "", "", fmt.Errorf("invalid line %q, expected KEY=VALUE", line)
+	}
+	val = strings.TrimSpace(val)
+	if val != "" && (val[0] == '"' || val[0] == '\'') {
+		end := strings.IndexByte(val[1:], val[0])
+		if end == -1 {
+			return "", "", fmt.Errorf("unterminated quoted value of key %q", key)
+		}
+		if rest := strings.TrimSpace(val[end+2:]); rest != "" && !strings.HasPrefix(rest, dotEnvCommentPrefix) {
+			return "", "", fmt.Errorf("unexpected %q after quoted value of key %q", rest, key)
+		}
+		return key, val[1 : end+1], nil
+	}
+	for i := range len(val) {
+		if strings.HasPrefix(val[i:], dotEnvCommentPrefix) && (i == 0 || val[i-1] == ' ' || val[i-1] == '\t') {
+			return key, strings.TrimSpace(val[:i]), nil
+		}
+	}
+	return key, val, nil
+}
+
+// validDotEnvKey returns whether key is a valid environment variable name, made of
+// letters, digits, and underscores, not starting with a digit.
+func validDotEnvKey(key string) bool {
+	for i, r := range key {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return key != ""
+}
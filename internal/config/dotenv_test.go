@@ -0,0 +1,108 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"mega/internal/config"
+)
+
+func TestNewFromDotEnv(t *testing.T) {
+	tests := []struct {
+		name          string
+		files         []string
+		env           map[string]string
+		wantLevel     config.LogLevel
+		wantFormat    config.LogFormat
+		wantSyslogTag string
+	}{
+		{
+			name:          "export",
+			files:         []string{"export LOG_LEVEL=debug\nexport\tLOG_FORMAT=json\n"},
+			wantLevel:     config.LogLevelDebug,
+			wantFormat:    config.LogFormatJSON,
+			wantSyslogTag: "",
+		},
+		{
+			name: "comments",
+			files: []string{
+				"# Logging\n\nLOG_LEVEL=warn # inline comment\nLOG_FORMAT=json\t# tab comment\nLOG_SYSLOG_TAG=app#1 # not before the #\n",
+			},
+			wantLevel:     config.LogLevelWarn,
+			wantFormat:    config.LogFormatJSON,
+			wantSyslogTag: "app#1",
+		},
+		{
+			name:          "quoted values",
+			files:         []string{"LOG_SYSLOG_TAG=\"my # app\" # comment\nLOG_FORMAT='logfmt'\n"},
+			wantLevel:     config.DefaultLogLevel,
+			wantFormat:    config.LogFormatLogfmt,
+			wantSyslogTag: "my # app",
+		},
+		{
+			name: "later files override earlier ones",
+			files: []string{
+				"LOG_LEVEL=debug\nLOG_FORMAT=json\n",
+				"LOG_LEVEL=error\n",
+			},
+			wantLevel:  config.LogLevelError,
+			wantFormat: config.LogFormatJSON,
+		},
+		{
+			name: "environment overrides files",
+			files: []string{
+				"LOG_LEVEL=debug\nLOG_FORMAT=json\n",
+				"LOG_LEVEL=error\n",
+			},
+			env:        map[string]string{config.EnvLogLevel: "warn"},
+			wantLevel:  config.LogLevelWarn,
+			wantFormat: config.LogFormatJSON,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, val := range tt.env {
+				t.Setenv(key, val)
+			}
+			paths := make([]string, len(tt.files))
+			for i, file := range tt.files {
+				paths[i] = writeFile(t, ".env", file)
+			}
+			cfg, err := config.NewFromDotEnv(paths...)
+			if err != nil {
+				t.Fatalf("NewFromDotEnv() error = %v", err)
+			}
+			if got := cfg.LogLevel(); got != tt.wantLevel {
+				t.Errorf("LogLevel() = %q, want %q", got, tt.wantLevel)
+			}
+			if got := cfg.LogFormat(); got != tt.wantFormat {
+				t.Errorf("LogFormat() = %q, want %q", got, tt.wantFormat)
+			}
+			if got := cfg.LogSyslogTag(); got != tt.wantSyslogTag {
+				t.Errorf("LogSyslogTag() = %q, want %q", got, tt.wantSyslogTag)
+			}
+		})
+	}
+}
+
+func TestNewFromDotEnvInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		wantLine string
+	}{
+		{name: "missing assignment", file: "LOG_LEVEL=debug\nLOG_FORMAT\n", wantLine: ":2: invalid line"},
+		{name: "invalid key", file: "# comment\n\n1LOG=debug\n", wantLine: ":3: invalid line"},
+		{name: "unterminated quote", file: "LOG_SYSLOG_TAG=\"app\n", wantLine: ":1: unterminated quoted value"},
+		{name: "text after quote", file: "LOG_SYSLOG_TAG='app' tag\n", wantLine: ":1: unexpected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, ".env", tt.file)
+			_, err := config.NewFromDotEnv(path)
+			if err == nil || !strings.Contains(err.Error(), path+tt.wantLine) {
+				t.Errorf("NewFromDotEnv() error = %v, want %q", err, path+tt.wantLine)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+type (
+	// FieldError represents an issue with the value of an environment variable
+	// configuring a field of the [Config].
+	//
+	// FieldError values are joined into the error returned when the configuration
+	// cannot be loaded, and can be retrieved with [errors.As].
+	FieldError struct {
+		// EnvVar is the name of the environment variable.
+		EnvVar string
+
+		// Value is the raw value of the environment variable.
+		Value string
+
+		// Reason describes the issue (e.g., "invalid log level").
+		Reason string
+
+		// Hint describes how to fix the issue, or is empty when there is none (e.g.,
+		// "expected one of: debug, info, warn, error").
+		Hint string
+
+		// Err is the underlying error, or nil when there is none.
+		Err error
+	}
+)
+
+// Error returns the description of the issue, formatted as
+// "<reason> (<env var>) got=<value>[: <err>][; <hint>]".
+func (e *FieldError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s) got=%q", e.Reason, e.EnvVar, e.Value)
+	if e.Err != nil {
+		fmt.Fprintf(&b, ": %v", e.Err)
+	}
+	if e.Hint != "" {
+		fmt.Fprintf(&b, "; %s", e.Hint)
+	}
+	return b.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// hintOneOf returns a hint listing the accepted values.
+func hintOneOf[T ~string](values ...T) string {
+	return "expected one of: " + strings.Join(enumStrings(values), ", ")
+}
@@ -0,0 +1,58 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+)
+
+type (
+	// fanoutHandler is a [slog.Handler] passing the log records to every wrapped
+	// handler enabling their level.
+	fanoutHandler struct {
+		handlers []slog.Handler
+	}
+)
+
+// newFanoutHandler creates and returns a new fanoutHandler wrapping handlers.
+func newFanoutHandler(handlers ...slog.Handler) *fanoutHandler {
+	return &fanoutHandler{
+		handlers: handlers,
+	}
+}
+
+func (h *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *fanoutHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, r.Level) {
+			// Each handler gets its own copy, as handlers may modify the record.
+			errs = append(errs, handler.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return newFanoutHandler(handlers...)
+}
+
+func (h *fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return newFanoutHandler(handlers...)
+}
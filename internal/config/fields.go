@@ -0,0 +1,476 @@
+package config
+
+import (
+	"maps"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type (
+	// field describes an environment variable supported by the application
+	// configuration and the setting it configures, if any, in a single place from
+	// which the specifications, see [EnvSpecs], and the renderings of the
+	// configuration (e.g., [Config.String], [Config.Diff], and [Config.Environ])
+	// are derived.
+	field struct {
+		// label is the human-readable name of the setting (e.g., "Log level").
+		label string
+
+		// spec is the specification of the environment variable.
+		spec EnvSpec
+
+		// value returns the setting of c rendered as the value of the environment
+		// variable, or is nil for the environment variables that only contribute to
+		// the setting of another (e.g., [EnvServerHost]).
+		value func(c *Config) string
+
+		// typed returns the setting of c as its Go value (e.g., a [time.Duration]),
+		// or is nil when it is the string returned by value.
+		typed func(c *Config) any
+
+		// sensitive reports whether the value discloses details worth hiding from
+		// snapshots shared for debugging, on top of the keys configured with
+		// [WithRedactedKeys].
+		sensitive bool
+
+		// credentials reports whether the value may embed credentials, as the
+		// userinfo of URLs or as query parameters, masked when displayed, see
+		// sanitizeForDisplay.
+		credentials bool
+	}
+)
+
+var (
+	// defaultServerHost and defaultServerPort define the defaults of [EnvServerHost]
+	// and [EnvServerPort], those of [DefaultServerAddress].
+	defaultServerHost, defaultServerPort, _ = net.SplitHostPort(DefaultServerAddress)
+
+	// boolValues defines the values accepted by the boolean environment variables.
+	boolValues = slices.Concat(boolTrueValues, boolFalseValues)
+
+	// fields defines every environment variable supported by the application
+	// configuration, in the order they are documented.
+	fields = []field{
+		{
+			label: "Log level",
+			spec: EnvSpec{
+				Name:          EnvLogLevel,
+				Default:       string(DefaultLogLevel),
+				Description:   "Severity or verbosity of log records.",
+				AllowedValues: enumStrings(logLevels),
+			},
+			value: func(c *Config) string { return string(c.logLevel) },
+			typed: func(c *Config) any { return c.logLevel },
+		},
+		{
+			label: "Log levels by name",
+			spec: EnvSpec{
+				Name:        EnvLogLevelsByName,
+				Description: "Comma-separated name=level levels of the loggers named by their logger attribute.",
+			},
+			value: func(c *Config) string {
+				pairs := make([]string, 0, len(c.logLevelsByName))
+				for _, name := range slices.Sorted(maps.Keys(c.logLevelsByName)) {
+					pairs = append(pairs, name+logLevelsByNameKeyValueSeparator+string(c.logLevelsByName[name]))
+				}
+				return strings.Join(pairs, logLevelsByNameSeparator)
+			},
+			typed: func(c *Config) any { return maps.Clone(c.logLevelsByName) },
+		},
+		{
+			label: "Log format",
+			spec: EnvSpec{
+				Name:          EnvLogFormat,
+				Default:       string(DefaultLogFormat),
+				Description:   "Encoding style of log records.",
+				AllowedValues: enumStrings(logFormats),
+			},
+			value: func(c *Config) string { return string(c.logFormat) },
+			typed: func(c *Config) any { return c.logFormat },
+		},
+		{
+			label: "Log output",
+			spec: EnvSpec{
+				Name:        EnvLogOutput,
+				Default:     string(DefaultLogOutput),
+				Description: `Comma-separated destination streams of log records: "stdout", "stderr", "discard", or a file path.`,
+			},
+			value:       func(c *Config) string { return string(c.LogOutput()) },
+			typed:       func(c *Config) any { return slices.Clone(c.logOutputs) },
+			credentials: true,
+		},
+		{
+			label: "Log secondary output",
+			spec: EnvSpec{
+				Name:        EnvLogSecondaryOutput,
+				Description: "Comma-separated destination streams to which log records are also written, encoded with the secondary format.",
+			},
+			value:       func(c *Config) string { return joinLogOutputs(c.logSecondaryOutputs) },
+			typed:       func(c *Config) any { return slices.Clone(c.logSecondaryOutputs) },
+			credentials: true,
+		},
+		{
+			label: "Log secondary format",
+			spec: EnvSpec{
+				Name:          EnvLogSecondaryFormat,
+				Default:       string(DefaultLogFormat),
+				Description:   "Encoding style of log records written to the secondary output.",
+				AllowedValues: enumStrings(logFormats),
+			},
+			value: func(c *Config) string { return string(c.logSecondaryFormat) },
+			typed: func(c *Config) any { return c.logSecondaryFormat },
+		},
+		{
+			label: "Log file max size",
+			spec: EnvSpec{
+				Name:        EnvLogFileMaxSizeMB,
+				Default:     strconv.Itoa(DefaultLogFileMaxSizeMB),
+				Description: "Maximum size, in megabytes, of a log file before it is rotated (0 disables rotation).",
+			},
+			value: func(c *Config) string { return strconv.Itoa(c.logFileMaxSizeMB) },
+			typed: func(c *Config) any { return c.logFileMaxSizeMB },
+		},
+		{
+			label: "Log file max backups",
+			spec: EnvSpec{
+				Name:        EnvLogFileMaxBackups,
+				Default:     strconv.Itoa(DefaultLogFileMaxBackups),
+				Description: "Maximum number of rotated log files to retain (0 retains all).",
+			},
+			value: func(c *Config) string { return strconv.Itoa(c.logFileMaxBackups) },
+			typed: func(c *Config) any { return c.logFileMaxBackups },
+		},
+		{
+			label: "Log file max age",
+			spec: EnvSpec{
+				Name:        EnvLogFileMaxAgeDays,
+				Default:     strconv.Itoa(DefaultLogFileMaxAgeDays),
+				Description: "Maximum number of days to retain rotated log files (0 retains all).",
+			},
+			value: func(c *Config) string { return strconv.Itoa(c.logFileMaxAgeDays) },
+			typed: func(c *Config) any { return c.logFileMaxAgeDays },
+		},
+		{
+			label: "Log add source",
+			spec: EnvSpec{
+				Name:          EnvLogAddSource,
+				Default:       strconv.FormatBool(DefaultLogAddSource),
+				Description:   "Whether log records include the source file and line of their caller.",
+				AllowedValues: boolValues,
+			},
+			value: func(c *Config) string { return strconv.FormatBool(c.logAddSource) },
+			typed: func(c *Config) any { return c.logAddSource },
+		},
+		{
+			label: "Log time format",
+			spec: EnvSpec{
+				Name:        EnvLogTimeFormat,
+				Default:     DefaultLogTimeFormat,
+				Description: `Format of the time of log records: "unix", "rfc3339", or a Go reference layout.`,
+			},
+			value: func(c *Config) string { return c.logTimeFormat },
+		},
+		{
+			label: "Log time UTC",
+			spec: EnvSpec{
+				Name:          EnvLogTimeUTC,
+				Default:       strconv.FormatBool(DefaultLogTimeUTC),
+				Description:   "Whether the time of log records is converted to UTC.",
+				AllowedValues: boolValues,
+			},
+			value: func(c *Config) string { return strconv.FormatBool(c.logTimeUTC) },
+			typed: func(c *Config) any { return c.logTimeUTC },
+		},
+		{
+			label: "Log color",
+			spec: EnvSpec{
+				Name:          EnvLogColor,
+				Default:       string(DefaultLogColor),
+				Description:   "When the levels of text log records are colorized.",
+				AllowedValues: enumStrings(logColors),
+			},
+			value: func(c *Config) string { return string(c.logColor) },
+			typed: func(c *Config) any { return c.logColor },
+		},
+		{
+			label: "Log syslog tag",
+			spec: EnvSpec{
+				Name:        EnvLogSyslogTag,
+				Default:     DefaultLogSyslogTag,
+				Description: "Tag of the log records written to syslog, the program name if empty.",
+			},
+			value: func(c *Config) string { return c.logSyslogTag },
+		},
+		{
+			label: "Log sample initial",
+			spec: EnvSpec{
+				Name:        EnvLogSampleInitial,
+				Default:     strconv.Itoa(DefaultLogSampleInitial),
+				Description: "Records below warn logged per second with the same level and message before sampling (0 disables sampling).",
+			},
+			value: func(c *Config) string { return strconv.Itoa(c.logSampleInitial) },
+			typed: func(c *Config) any { return c.logSampleInitial },
+		},
+		{
+			label: "Log sample thereafter",
+			spec: EnvSpec{
+				Name:        EnvLogSampleThereafter,
+				Default:     strconv.Itoa(DefaultLogSampleThereafter),
+				Description: "Every Nth record logged past the initial ones in a second (0 drops them all).",
+			},
+			value: func(c *Config) string { return strconv.Itoa(c.logSampleThereafter) },
+			typed: func(c *Config) any { return c.logSampleThereafter },
+		},
+		{
+			label: "Log sample rate",
+			spec: EnvSpec{
+				Name:        EnvLogSampleRate,
+				Default:     strconv.FormatFloat(DefaultLogSampleRate, 'g', -1, 64),
+				Description: "Fraction of the records below warn logged, picked at random (1 logs them all).",
+			},
+			value: func(c *Config) string { return strconv.FormatFloat(c.logSampleRate, 'g', -1, 64) },
+			typed: func(c *Config) any { return c.logSampleRate },
+		},
+		{
+			label: "Log default attributes",
+			spec: EnvSpec{
+				Name:        EnvLogDefaultAttrs,
+				Description: "Comma-separated key=value attributes added to every log record.",
+			},
+			value: func(c *Config) string { return joinStrings(c.logDefaultAttrs, logDefaultAttrsSeparator) },
+			typed: func(c *Config) any { return slices.Clone(c.logDefaultAttrs) },
+		},
+		{
+			label: "Server address",
+			spec: EnvSpec{
+				Name:        EnvServerAddress,
+				Default:     DefaultServerAddress,
+				Description: `Server's address, as "<host>:port", "unix://<path>", or "auto".`,
+			},
+			value: func(c *Config) string {
+				if c.serverNetwork == ServerNetworkUnix {
+					return unixAddressPrefix + c.serverAddress
+				}
+				return c.serverAddress
+			},
+			credentials: true,
+		},
+		{
+			label: "Server host",
+			spec: EnvSpec{
+				Name:        EnvServerHost,
+				Default:     defaultServerHost,
+				Description: "Server's host, used when the server's address is unset.",
+			},
+		},
+		{
+			label: "Server port",
+			spec: EnvSpec{
+				Name:        EnvServerPort,
+				Default:     defaultServerPort,
+				Description: "Server's port, used when the server's address is unset.",
+			},
+		},
+		{
+			label: "Server read timeout",
+			spec: EnvSpec{
+				Name:        EnvServerReadTimeout,
+				Default:     DefaultServerReadTimeout.String(),
+				Description: "Server's read timeout.",
+			},
+			value: func(c *Config) string { return c.serverReadTimeout.String() },
+			typed: func(c *Config) any { return c.serverReadTimeout },
+		},
+		{
+			label: "Server read header timeout",
+			spec: EnvSpec{
+				Name:        EnvServerReadHeaderTimeout,
+				Default:     DefaultServerReadHeaderTimeout.String(),
+				Description: "Server's read header timeout.",
+			},
+			value: func(c *Config) string { return c.serverReadHeaderTimeout.String() },
+			typed: func(c *Config) any { return c.serverReadHeaderTimeout },
+		},
+		{
+			label: "Server write timeout",
+			spec: EnvSpec{
+				Name:        EnvServerWriteTimeout,
+				Default:     DefaultServerWriteTimeout.String(),
+				Description: "Server's write timeout.",
+			},
+			value: func(c *Config) string { return c.serverWriteTimeout.String() },
+			typed: func(c *Config) any { return c.serverWriteTimeout },
+		},
+		{
+			label: "Server idle timeout",
+			spec: EnvSpec{
+				Name:        EnvServerIdleTimeout,
+				Default:     DefaultServerIdleTimeout.String(),
+				Description: "Server's idle timeout.",
+			},
+			value: func(c *Config) string { return c.serverIdleTimeout.String() },
+			typed: func(c *Config) any { return c.serverIdleTimeout },
+		},
+		{
+			label: "Server request timeout",
+			spec: EnvSpec{
+				Name:        EnvServerRequestTimeout,
+				Default:     DefaultServerRequestTimeout.String(),
+				Description: "Server's request processing deadline, 0 for none.",
+			},
+			value: func(c *Config) string { return c.serverRequestTimeout.String() },
+			typed: func(c *Config) any { return c.serverRequestTimeout },
+		},
+		{
+			label: "Server shutdown timeout",
+			spec: EnvSpec{
+				Name:        EnvServerShutdownTimeout,
+				Default:     DefaultServerShutdownTimeout.String(),
+				Description: "Server's shutdown timeout.",
+			},
+			value: func(c *Config) string { return c.serverShutdownTimeout.String() },
+			typed: func(c *Config) any { return c.serverShutdownTimeout },
+		},
+		{
+			label: "Server shutdown grace",
+			spec: EnvSpec{
+				Name:        EnvServerShutdownGrace,
+				Default:     DefaultServerShutdownGrace.String(),
+				Description: "Server's shutdown grace period, serving requests before shutting down.",
+			},
+			value: func(c *Config) string { return c.serverShutdownGrace.String() },
+			typed: func(c *Config) any { return c.serverShutdownGrace },
+		},
+		{
+			label: "Server max header bytes",
+			spec: EnvSpec{
+				Name:        EnvServerMaxHeaderBytes,
+				Default:     strconv.Itoa(DefaultServerMaxHeaderBytes),
+				Description: "Server's maximum header bytes, optionally with a unit (e.g., 64KB, 1MiB).",
+			},
+			value: func(c *Config) string { return strconv.Itoa(c.serverMaxHeaderBytes) },
+			typed: func(c *Config) any { return c.serverMaxHeaderBytes },
+		},
+		{
+			label: "Server max connections",
+			spec: EnvSpec{
+				Name:        EnvServerMaxConns,
+				Default:     strconv.Itoa(DefaultServerMaxConns),
+				Description: "Server's maximum simultaneous connections, 0 for unlimited.",
+			},
+			value: func(c *Config) string { return strconv.Itoa(c.serverMaxConns) },
+			typed: func(c *Config) any { return c.serverMaxConns },
+		},
+		{
+			label: "Server access log",
+			spec: EnvSpec{
+				Name:          EnvServerAccessLog,
+				Default:       strconv.FormatBool(DefaultServerAccessLog),
+				Description:   "Whether the server logs the requests it handles.",
+				AllowedValues: boolValues,
+			},
+			value: func(c *Config) string { return strconv.FormatBool(c.serverAccessLog) },
+			typed: func(c *Config) any { return c.serverAccessLog },
+		},
+		{
+			label: "Server HTTP/2",
+			spec: EnvSpec{
+				Name:          EnvServerHTTP2,
+				Default:       strconv.FormatBool(DefaultServerHTTP2),
+				Description:   "Whether the server serves HTTP/2 over TLS connections.",
+				AllowedValues: boolValues,
+			},
+			value: func(c *Config) string { return strconv.FormatBool(c.serverHTTP2) },
+			typed: func(c *Config) any { return c.serverHTTP2 },
+		},
+		{
+			label: "Server keep-alive",
+			spec: EnvSpec{
+				Name:          EnvServerKeepAlive,
+				Default:       strconv.FormatBool(DefaultServerKeepAlive),
+				Description:   "Whether the server keeps connections alive between requests.",
+				AllowedValues: boolValues,
+			},
+			value: func(c *Config) string { return strconv.FormatBool(c.serverKeepAlive) },
+			typed: func(c *Config) any { return c.serverKeepAlive },
+		},
+		{
+			label: "Server pprof",
+			spec: EnvSpec{
+				Name:          EnvServerPprof,
+				Default:       strconv.FormatBool(DefaultServerPprof),
+				Description:   "Whether the runtime profiling data is served. It discloses the application's internals, so it must not be exposed publicly.",
+				AllowedValues: boolValues,
+			},
+			value: func(c *Config) string { return strconv.FormatBool(c.serverPprof) },
+			typed: func(c *Config) any { return c.serverPprof },
+		},
+		{
+			label: "Server pprof path prefix",
+			spec: EnvSpec{
+				Name:        EnvServerPprofPathPrefix,
+				Default:     DefaultServerPprofPathPrefix,
+				Description: "URL path prefix under which the runtime profiling data is served.",
+			},
+			value: func(c *Config) string { return c.serverPprofPathPrefix },
+		},
+		{
+			label: "Server trusted proxies",
+			spec: EnvSpec{
+				Name:        EnvServerTrustedProxies,
+				Description: "Comma-separated CIDR prefixes of the reverse proxies trusted to forward client information.",
+			},
+			value: func(c *Config) string { return joinStrings(c.serverTrustedProxies, serverTrustedProxiesSeparator) },
+			typed: func(c *Config) any { return slices.Clone(c.serverTrustedProxies) },
+		},
+		{
+			label: "Server TLS cert file",
+			spec: EnvSpec{
+				Name:        EnvServerTLSCertFile,
+				Description: "Path of the server's TLS certificate file, set along with the key file.",
+			},
+			value: func(c *Config) string { return c.serverTLSCertFile },
+		},
+		{
+			label: "Server TLS key file",
+			spec: EnvSpec{
+				Name:        EnvServerTLSKeyFile,
+				Description: "Path of the server's TLS private key file, set along with the cert file.",
+			},
+			value:     func(c *Config) string { return c.serverTLSKeyFile },
+			sensitive: true,
+		},
+		{
+			label: "Metrics address",
+			spec: EnvSpec{
+				Name:        EnvMetricsAddress,
+				Description: `Metrics server's address, as "<host>:port", distinct from the server's address. Metrics are disabled when unset.`,
+			},
+			value:       func(c *Config) string { return c.metricsAddress },
+			credentials: true,
+		},
+		{
+			label: "Metrics path",
+			spec: EnvSpec{
+				Name:        EnvMetricsPath,
+				Default:     DefaultMetricsPath,
+				Description: "URL path at which the metrics server exposes the metrics.",
+			},
+			value: func(c *Config) string { return c.metricsPath },
+		},
+	}
+)
+
+// lookupField returns the field describing the environment variable named by key,
+// and whether there is one.
+func lookupField(key string) (field, bool) {
+	i := slices.IndexFunc(fields, func(f field) bool {
+		return f.spec.Name == key
+	})
+	if i == -1 {
+		return field{}, false
+	}
+	return fields[i], true
+}
@@ -0,0 +1,113 @@
+package config
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+var (
+	// unrenderedConfigFields lists the fields of [Config] holding the options and
+	// the state of the loading rather than settings, which are therefore not
+	// represented in the fields table.
+	unrenderedConfigFields = []string{
+		"logOutputFallback",
+		"logOutputBaseDir",
+		"confinedLogOutput",
+		"onShutdown",
+		"redactedKeys",
+		"maxDurations",
+		"sourcePath",
+		"sources",
+		"warnings",
+		"resolvedAddress",
+	}
+
+	// configFieldEnvs defines, for each field of [Config] holding a setting, the
+	// environment variable of the entry of the fields table rendering it.
+	configFieldEnvs = map[string]string{
+		"logLevel":                EnvLogLevel,
+		"logLevelsByName":         EnvLogLevelsByName,
+		"logFormat":               EnvLogFormat,
+		"logOutputs":              EnvLogOutput,
+		"logSecondaryOutputs":     EnvLogSecondaryOutput,
+		"logSecondaryFormat":      EnvLogSecondaryFormat,
+		"logFileMaxSizeMB":        EnvLogFileMaxSizeMB,
+		"logFileMaxBackups":       EnvLogFileMaxBackups,
+		"logFileMaxAgeDays":       EnvLogFileMaxAgeDays,
+		"logAddSource":            EnvLogAddSource,
+		"logTimeFormat":           EnvLogTimeFormat,
+		"logTimeUTC":              EnvLogTimeUTC,
+		"logColor":                EnvLogColor,
+		"logSyslogTag":            EnvLogSyslogTag,
+		"logSampleInitial":        EnvLogSampleInitial,
+		"logSampleThereafter":     EnvLogSampleThereafter,
+		"logSampleRate":           EnvLogSampleRate,
+		"logDefaultAttrs":         EnvLogDefaultAttrs,
+		"serverNetwork":           EnvServerAddress,
+		"serverAddress":           EnvServerAddress,
+		"serverReadTimeout":       EnvServerReadTimeout,
+		"serverReadHeaderTimeout": EnvServerReadHeaderTimeout,
+		"serverWriteTimeout":      EnvServerWriteTimeout,
+		"serverIdleTimeout":       EnvServerIdleTimeout,
+		"serverRequestTimeout":    EnvServerRequestTimeout,
+		"serverShutdownTimeout":   EnvServerShutdownTimeout,
+		"serverShutdownGrace":     EnvServerShutdownGrace,
+		"serverMaxHeaderBytes":    EnvServerMaxHeaderBytes,
+		"serverMaxConns":          EnvServerMaxConns,
+		"serverAccessLog":         EnvServerAccessLog,
+		"serverHTTP2":             EnvServerHTTP2,
+		"serverKeepAlive":         EnvServerKeepAlive,
+		"serverPprof":             EnvServerPprof,
+		"serverPprofPathPrefix":   EnvServerPprofPathPrefix,
+		"serverTrustedProxies":    EnvServerTrustedProxies,
+		"serverTLSCertFile":       EnvServerTLSCertFile,
+		"serverTLSKeyFile":        EnvServerTLSKeyFile,
+		"metricsAddress":          EnvMetricsAddress,
+		"metricsPath":             EnvMetricsPath,
+	}
+)
+
+func TestFieldsCoverConfig(t *testing.T) {
+	typ := reflect.TypeOf(Config{})
+	names := make([]string, typ.NumField())
+	for i := range typ.NumField() {
+		names[i] = typ.Field(i).Name
+	}
+	for _, name := range unrenderedConfigFields {
+		if !slices.Contains(names, name) {
+			t.Errorf("unrenderedConfigFields lists Config.%s, which does not exist", name)
+		}
+	}
+	for name := range configFieldEnvs {
+		if !slices.Contains(names, name) {
+			t.Errorf("configFieldEnvs lists Config.%s, which does not exist", name)
+		}
+	}
+	rendered := make(map[string]bool)
+	for _, f := range fields {
+		if f.value != nil {
+			rendered[f.spec.Name] = false
+		}
+	}
+	for _, name := range names {
+		if slices.Contains(unrenderedConfigFields, name) {
+			continue
+		}
+		env, ok := configFieldEnvs[name]
+		if !ok {
+			t.Errorf("Config.%s is missing from configFieldEnvs; add it to the fields table", name)
+			continue
+		}
+		if _, ok := rendered[env]; !ok {
+			t.Errorf("Config.%s is rendered as %s, which the fields table lacks", name, env)
+			continue
+		}
+		rendered[env] = true
+	}
+	for env, covered := range rendered {
+		if !covered {
+			t.Errorf("the fields table renders %s, which no field of Config holds", env)
+		}
+	}
+}
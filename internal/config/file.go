@@ -0,0 +1,251 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// envXDGConfigHome defines the environment variable name of the base directory
+	// of user-specific configuration files, per the XDG Base Directory
+	// Specification.
+	envXDGConfigHome = "XDG_CONFIG_HOME"
+)
+
+const (
+	// formatJSON defines the format of the JSON configuration files.
+	formatJSON = "json"
+
+	// formatYAML defines the format of the YAML configuration files.
+	formatYAML = "yaml"
+
+	// formatEnv defines the format of the dotenv configuration files.
+	formatEnv = "env"
+)
+
+var (
+	// formats defines the formats accepted by [NewFromReader], in the order they
+	// are reported.
+	formats = []string{formatJSON, formatYAML, formatEnv}
+)
+
+// NewFromFile creates and returns a new [Config] instance like [New], falling back
+// to the values read from the configuration file at path for the environment
+// variables that are unset.
+//
+// The file is parsed as YAML if its extension is ".yaml" or ".yml", and as JSON
+// otherwise. It holds a single object whose keys are the lowercase names of the
+// environment variables (e.g., "log_level", "server_address") and whose values
+// are strings, numbers, or booleans, accepted as the corresponding environment
+// variables would be. Unknown keys are reported as errors.
+//
+// The string values may reference environment variables, as "${VAR}" or "$VAR"
+// (e.g., ":${PORT}"), replaced by their values, while "$$" stands for a literal
+// "$". The references to unset environment variables are replaced by an empty
+// string and reported as warnings, see [Config.Warnings].
+func NewFromFile(path string, opts ...Option) (*Config, error) {
+	o := newOptions(opts)
+	start := time.Now()
+	file, err := readFile(path)
+	o.observe(LoadPhaseReadFile, start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	cfg, err := load(context.Background(), o, newFileLoader(o, file))
+	if err != nil {
+		return nil, err
+	}
+	cfg.sourcePath = path
+	return cfg, nil
+}
+
+// NewFromReader creates and returns a new [Config] instance like [NewFromFile],
+// falling back to the values read from r, encoded in format: "json", "yaml", or
+// "env" for the dotenv format read by [NewFromDotEnv]. It suits configurations
+// that do not live in a file of their own, such as those embedded in archives or
+// piped to the application.
+//
+// The environment variables override the values read from r, unless
+// [WithNoEnvOverride] is given.
+func NewFromReader(r io.Reader, format string, opts ...Option) (*Config, error) {
+	o := newOptions(opts)
+	start := time.Now()
+	values, err := readValues(r, format)
+	o.observe(LoadPhaseReadFile, start)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return load(context.Background(), o, newFileLoader(o, values))
+}
+
+// newFileLoader creates and returns a new loader falling back to the values read
+// from a configuration file, ignoring the environment variables if configured
+// with [WithNoEnvOverride].
+func newFileLoader(o *options, values map[string]string) *loader {
+	if o.noEnvOverride {
+		return newLoader(mapLookupEnv(nil), mapEnvNames(nil), values)
+	}
+	return newLoader(os.LookupEnv, osEnvNames, values)
+}
+
+// readValues reads the configuration encoded in format from r, returning its
+// values keyed by environment variable name.
+func readValues(r io.Reader, format string) (map[string]string, error) {
+	if !slices.Contains(formats, format) {
+		return nil, fmt.Errorf("unsupported configuration format %q, expected one of: %s", format, strings.Join(formats, ", "))
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration (%s): %w", format, err)
+	}
+	values, err := parseValues(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration (%s): %w", format, err)
+	}
+	return values, nil
+}
+
+// NewFromStandardPaths creates and returns a new [Config] instance like
+// [NewFromFile], with the first configuration file found at the standard paths of
+// the application named appName, searched in order:
+//
+//   - $XDG_CONFIG_HOME/<appName>/config.yaml, then config.json
+//   - $HOME/.config/<appName>/config.yaml, then config.json
+//   - /etc/<appName>/config.yaml
+//
+// Missing files are skipped, and if none is found, the configuration is loaded
+// like [New]. The path of the file used is returned by [Config.SourcePath].
+func NewFromStandardPaths(appName string, opts ...Option) (*Config, error) {
+	for _, path := range standardPaths(appName) {
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) || err == nil && info.IsDir() {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load configuration: failed to read configuration file (%s): %w", path, err)
+		}
+		return NewFromFile(path, opts...)
+	}
+	return New(opts...)
+}
+
+// standardPaths returns the standard paths of the configuration file of the
+// application named appName, in the order they are searched.
+func standardPaths(appName string) []string {
+	var dirs []string
+	if dir := os.Getenv(envXDGConfigHome); dir != "" {
+		dirs = append(dirs, filepath.Join(dir, appName))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", appName))
+	}
+	var paths []string
+	for _, dir := range dirs {
+		paths = append(paths, filepath.Join(dir, "config.yaml"), filepath.Join(dir, "config.json"))
+	}
+	return append(paths, filepath.Join("/etc", appName, "config.yaml"))
+}
+
+// readFile reads the configuration file at path, returning its values keyed by
+// environment variable name.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read configuration file (%s): %w", path, err)
+	}
+	format := formatJSON
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format = formatYAML
+	}
+	values, err := parseValues(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration file (%s): %w", path, err)
+	}
+	return values, nil
+}
+
+// parseValues parses data, encoded in format, returning its values keyed by
+// environment variable name.
+func parseValues(data []byte, format string) (map[string]string, error) {
+	switch format {
+	case formatYAML:
+		return parseYAML(data)
+	case formatEnv:
+		values := make(map[string]string)
+		return values, parseDotEnv("", data, values)
+	}
+	return parseJSON(data)
+}
+
+func parseJSON(data []byte) (map[string]string, error) {
+	var raw map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return fileValues(raw, func(val any) (string, bool) {
+		switch val := val.(type) {
+		case string:
+			return val, true
+		case json.Number:
+			return val.String(), true
+		case bool:
+			return strconv.FormatBool(val), true
+		}
+		return "", false
+	})
+}
+
+func parseYAML(data []byte) (map[string]string, error) {
+	var raw map[string]yaml.Node
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return fileValues(raw, func(val yaml.Node) (string, bool) {
+		// Scalars are kept as written, so that they are accepted as the
+		// corresponding environment variables would be.
+		return val.Value, val.Kind == yaml.ScalarNode && val.Tag != "!!null"
+	})
+}
+
+// fileValues returns the values of raw keyed by environment variable name, each
+// rendered by scalar, which reports whether the value is a string, number, or
+// boolean.
+func fileValues[V any](raw map[string]V, scalar func(V) (string, bool)) (map[string]string, error) {
+	known := make(map[string]bool)
+	for _, spec := range EnvSpecs() {
+		known[spec.Name] = true
+	}
+	values := make(map[string]string, len(raw))
+	var errs []error
+	for _, key := range slices.Sorted(maps.Keys(raw)) {
+		name := strings.ToUpper(key)
+		if !known[name] {
+			errs = append(errs, fmt.Errorf("unknown key %q", key))
+			continue
+		}
+		val, ok := scalar(raw[key])
+		if !ok {
+			errs = append(errs, fmt.Errorf("invalid value of key %q, expected a string, number, or boolean", key))
+			continue
+		}
+		values[name] = val
+	}
+	return values, errors.Join(errs...)
+}
@@ -0,0 +1,149 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mega/internal/config"
+)
+
+// writeFile writes content to the file name in a temporary directory, returning
+// its path.
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewFromFileExpansion(t *testing.T) {
+	t.Setenv("MEGA_TEST_PORT", "9090")
+	t.Setenv("MEGA_TEST_NAME", "api")
+	tests := []struct {
+		name        string
+		file        string
+		want        string
+		wantWarning string
+	}{
+		{
+			name: "defined with braces",
+			file: `{"log_syslog_tag": "app-${MEGA_TEST_NAME}"}`,
+			want: "app-api",
+		},
+		{
+			name: "defined without braces",
+			file: `{"log_syslog_tag": "$MEGA_TEST_NAME:$MEGA_TEST_PORT"}`,
+			want: "api:9090",
+		},
+		{
+			name:        "undefined",
+			file:        `{"log_syslog_tag": "app-${MEGA_TEST_UNDEFINED}"}`,
+			want:        "app-",
+			wantWarning: "MEGA_TEST_UNDEFINED",
+		},
+		{
+			name: "escaped",
+			file: `{"log_syslog_tag": "cost-$$MEGA_TEST_NAME"}`,
+			want: "cost-$MEGA_TEST_NAME",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.NewFromFile(writeFile(t, "config.json", tt.file))
+			if err != nil {
+				t.Fatalf("NewFromFile() error = %v", err)
+			}
+			if got := cfg.LogSyslogTag(); got != tt.want {
+				t.Errorf("LogSyslogTag() = %q, want %q", got, tt.want)
+			}
+			warnings := cfg.Warnings()
+			if tt.wantWarning == "" {
+				if len(warnings) > 0 {
+					t.Errorf("Warnings() = %q, want none", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tt.wantWarning) {
+				t.Errorf("Warnings() = %q, want one naming %s", warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestNewFromReader(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+	}{
+		{name: "JSON", format: "json", input: `{"log_level": "debug", "server_max_conns": 10, "server_http2": false}`},
+		{name: "YAML", format: "yaml", input: "log_level: debug\nserver_max_conns: 10\nserver_http2: false\n"},
+		{name: "dotenv", format: "env", input: "LOG_LEVEL=debug\nexport SERVER_MAX_CONNS=10\nSERVER_HTTP2=false # comment\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.NewFromReader(strings.NewReader(tt.input), tt.format)
+			if err != nil {
+				t.Fatalf("NewFromReader() error = %v", err)
+			}
+			if got := cfg.LogLevel(); got != config.LogLevelDebug {
+				t.Errorf("LogLevel() = %q, want %q", got, config.LogLevelDebug)
+			}
+			if got := cfg.ServerMaxConns(); got != 10 {
+				t.Errorf("ServerMaxConns() = %d, want 10", got)
+			}
+			if cfg.ServerHTTP2() {
+				t.Error("ServerHTTP2() = true, want false")
+			}
+		})
+	}
+}
+
+func TestNewFromReaderEnvOverride(t *testing.T) {
+	t.Setenv(config.EnvLogLevel, "warn")
+	tests := []struct {
+		name string
+		opts []config.Option
+		want config.LogLevel
+	}{
+		{name: "environment overrides", want: config.LogLevelWarn},
+		{name: "no environment override", opts: []config.Option{config.WithNoEnvOverride()}, want: config.LogLevelDebug},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.NewFromReader(strings.NewReader(`{"log_level": "debug"}`), "json", tt.opts...)
+			if err != nil {
+				t.Fatalf("NewFromReader() error = %v", err)
+			}
+			if got := cfg.LogLevel(); got != tt.want {
+				t.Errorf("LogLevel() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFromReaderInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		input   string
+		wantErr string
+	}{
+		{name: "malformed JSON", format: "json", input: `{"log_level": `, wantErr: "invalid configuration (json)"},
+		{name: "malformed YAML", format: "yaml", input: "log_level: [debug", wantErr: "invalid configuration (yaml)"},
+		{name: "malformed dotenv", format: "env", input: "LOG_LEVEL\n", wantErr: "invalid configuration (env): line 1"},
+		{name: "unsupported format", format: "toml", input: `log_level = "debug"`, wantErr: `unsupported configuration format "toml"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.NewFromReader(strings.NewReader(tt.input), tt.format)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewFromReader() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+)
+
+const (
+	// logNameKey defines the key of the attribute naming the logger of log records,
+	// whose level may be configured with [EnvLogLevelsByName].
+	logNameKey = "logger"
+)
+
+type (
+	// levelsHandler is a [slog.Handler] filtering the log records by the level
+	// configured for the logger named by their logNameKey attribute, if any, or
+	// by the root level otherwise, before passing them to the wrapped handler.
+	levelsHandler struct {
+		slog.Handler
+		levels  map[string]slog.Level
+		root    slog.Level
+		name    string
+		grouped bool
+	}
+)
+
+// newLevelsHandler creates and returns a new levelsHandler wrapping h, which must
+// enable every level enabled for any logger.
+func newLevelsHandler(h slog.Handler, root LogLevel, levels map[string]LogLevel) *levelsHandler {
+	slogLevels := make(map[string]slog.Level, len(levels))
+	for name, level := range levels {
+		slogLevels[name] = level.SlogLevel()
+	}
+	return &levelsHandler{
+		Handler: h,
+		levels:  slogLevels,
+		root:    root.SlogLevel(),
+	}
+}
+
+func (h *levelsHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	// Unless grouped, the records may still name their logger, so their level is
+	// only checked once they are handled.
+	if h.grouped && level < h.level(h.name) {
+		return false
+	}
+	return h.Handler.Enabled(ctx, level)
+}
+
+func (h *levelsHandler) Handle(ctx context.Context, r slog.Record) error {
+	name := h.name
+	if !h.grouped {
+		r.Attrs(func(a slog.Attr) bool {
+			if a.Key == logNameKey {
+				name = a.Value.String()
+				return false
+			}
+			return true
+		})
+	}
+	if r.Level < h.level(name) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *levelsHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.Handler = h.Handler.WithAttrs(attrs)
+	if !h.grouped {
+		for _, a := range attrs {
+			if a.Key == logNameKey {
+				clone.name = a.Value.String()
+			}
+		}
+	}
+	return &clone
+}
+
+func (h *levelsHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.Handler = h.Handler.WithGroup(name)
+	// The attributes added to a group are qualified by it, so they no longer name
+	// the logger.
+	clone.grouped = true
+	return &clone
+}
+
+// level returns the level of the logger named by name, or the root level if it is
+// not configured specifically.
+func (h *levelsHandler) level(name string) slog.Level {
+	if level, ok := h.levels[name]; ok {
+		return level
+	}
+	return h.root
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"net"
+	"sync"
+)
+
+type (
+	// limitListener is a [net.Listener] accepting at most a fixed number of
+	// simultaneous connections, blocking in Accept until an accepted connection is
+	// closed once the limit is reached.
+	limitListener struct {
+		net.Listener
+		sem       chan struct{}
+		done      chan struct{}
+		closeOnce sync.Once
+	}
+
+	// limitConn is a [net.Conn] accepted by a limitListener, releasing its slot
+	// once closed.
+	limitConn struct {
+		net.Conn
+		releaseOnce sync.Once
+		release     func()
+	}
+)
+
+// LimitListener returns a listener wrapping ln that accepts at most the configured
+// server's maximum simultaneous connections, blocking in Accept until an accepted
+// connection is closed once the limit is reached.
+//
+// If the limit is 0 (unlimited), ln is returned unchanged.
+func (c *Config) LimitListener(ln net.Listener) net.Listener {
+	if c.serverMaxConns == 0 {
+		return ln
+	}
+	return &limitListener{
+		Listener: ln,
+		sem:      make(chan struct{}, c.serverMaxConns),
+		done:     make(chan struct{}),
+	}
+}
+
+func (l *limitListener) Accept() (net.Conn, error) {
+	select {
+	case l.sem <- struct{}{}:
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		<-l.sem
+		return nil, err
+	}
+	return &limitConn{
+		Conn: conn,
+		release: func() {
+			<-l.sem
+		},
+	}, nil
+}
+
+func (l *limitListener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+	})
+	return l.Listener.Close()
+}
+
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.releaseOnce.Do(c.release)
+	return err
+}
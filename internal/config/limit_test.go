@@ -0,0 +1,95 @@
+package config_test
+
+import (
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"mega/internal/config"
+)
+
+func TestConfigLimitListener(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxConns int
+	}{
+		{name: "one connection", maxConns: 1},
+		{name: "three connections", maxConns: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(map[string]string{
+				config.EnvServerMaxConns: strconv.Itoa(tt.maxConns),
+			})
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			inner, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			ln := cfg.LimitListener(inner)
+			defer ln.Close()
+			accepted := make(chan net.Conn)
+			go func() {
+				for {
+					conn, err := ln.Accept()
+					if err != nil {
+						close(accepted)
+						return
+					}
+					accepted <- conn
+				}
+			}()
+			// The connections are established by the kernel regardless of Accept,
+			// so every client dials successfully.
+			for range tt.maxConns + 1 {
+				conn, err := net.Dial("tcp", inner.Addr().String())
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer conn.Close()
+			}
+			conns := make([]net.Conn, tt.maxConns)
+			for i := range conns {
+				select {
+				case conns[i] = <-accepted:
+				case <-time.After(5 * time.Second):
+					t.Fatalf("connection %d not accepted", i+1)
+				}
+			}
+			select {
+			case conn := <-accepted:
+				conn.Close()
+				t.Fatalf("connection %d accepted past the limit", tt.maxConns+1)
+			case <-time.After(100 * time.Millisecond):
+			}
+			conns[0].Close()
+			select {
+			case conn := <-accepted:
+				conn.Close()
+			case <-time.After(5 * time.Second):
+				t.Fatalf("connection %d not accepted once one closed", tt.maxConns+1)
+			}
+			for _, conn := range conns[1:] {
+				conn.Close()
+			}
+		})
+	}
+}
+
+func TestConfigLimitListenerUnlimited(t *testing.T) {
+	cfg, err := config.LoadFromMap(map[string]string{config.EnvServerMaxConns: "0"})
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	if got := cfg.LimitListener(ln); got != ln {
+		t.Errorf("LimitListener() = %v, want the listener unchanged", got)
+	}
+}
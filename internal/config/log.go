@@ -0,0 +1,344 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	// logfmtTimeKey defines the key used for the time of log records rendered as
+	// [LogFormatLogfmt].
+	logfmtTimeKey = "ts"
+)
+
+// LogHandler creates and returns a new [slog.Handler] writing log records to the
+// configured [LogOutput], filtered by the configured [LogLevel] and encoded with
+// the configured [LogFormat], with the configured [Config.LogDefaultAttrs].
+//
+// The records of the loggers named by a "logger" attribute (e.g., added with
+// [slog.Logger.With]) are filtered by [Config.LevelFor] their name instead.
+//
+// When [Config.LogSampleInitial] is non-zero, the records below [LogLevelWarn] are
+// sampled: per second, the first ones with the same level and message are logged,
+// then only every [Config.LogSampleThereafter]th. When [Config.LogSampleRate] is
+// below 1, only that fraction of the records below [LogLevelWarn], picked at
+// random, is logged.
+//
+// When [Config.LogSecondaryOutputs] are configured, the records are also written to
+// them, encoded with [Config.LogSecondaryFormat], filtered by the same level.
+//
+// The returned [io.Closer] releases the resources held by the log outputs and must
+// be closed once the handler is no longer used.
+func (c *Config) LogHandler() (slog.Handler, io.Closer, error) {
+	w, err := c.OpenLogOutput()
+	if err != nil {
+		return nil, nil, err
+	}
+	// The records of the loggers configured with a lower level than the root one
+	// must reach the levelsHandler.
+	level := c.logLevel.SlogLevel()
+	for _, l := range c.logLevelsByName {
+		level = min(level, l.SlogLevel())
+	}
+	h := c.formatHandler(w, c.logFormat, level)
+	var closer io.Closer = w
+	if len(c.logSecondaryOutputs) > 0 {
+		secondary, err := c.openLogOutputs(c.logSecondaryOutputs)
+		if err != nil {
+			w.Close()
+			return nil, nil, err
+		}
+		h = newFanoutHandler(h, c.formatHandler(secondary, c.logSecondaryFormat, level))
+		closer = &multiWriteCloser{closers: []io.Closer{w, secondary}}
+	}
+	if len(c.logDefaultAttrs) > 0 {
+		h = h.WithAttrs(c.logDefaultAttrs)
+	}
+	if len(c.logLevelsByName) > 0 {
+		h = newLevelsHandler(h, c.logLevel, c.logLevelsByName)
+	}
+	if c.logSampleInitial > 0 || c.logSampleRate < 1 {
+		h = newSamplingHandler(h, c.logSampleInitial, c.logSampleThereafter, c.logSampleRate)
+	}
+	return h, closer, nil
+}
+
+// formatHandler creates and returns a new [slog.Handler] writing the log records
+// of at least level to w, encoded with format.
+func (c *Config) formatHandler(w io.Writer, format LogFormat, level slog.Level) slog.Handler {
+	var replaceAttrs []replaceAttrFunc
+	if c.logTimeFormat != "" || c.logTimeUTC {
+		replaceAttrs = append(replaceAttrs, c.timeReplaceAttr)
+	}
+	if format == LogFormatLogfmt {
+		replaceAttrs = append(replaceAttrs, logfmtReplaceAttr)
+	}
+	opts := &slog.HandlerOptions{
+		AddSource:   c.logAddSource,
+		Level:       level,
+		ReplaceAttr: chainReplaceAttrs(replaceAttrs),
+	}
+	switch format {
+	case LogFormatJSON:
+		return slog.NewJSONHandler(w, opts)
+	case LogFormatLogfmt:
+		return slog.NewTextHandler(w, opts)
+	}
+	if c.colorize(w) {
+		return slog.NewTextHandler(colorWriter{w}, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// colorize returns whether the levels of log records written to w are colorized,
+// resolving [LogColorAuto] by checking whether w is a terminal.
+func (c *Config) colorize(w io.Writer) bool {
+	switch c.logColor {
+	case LogColorAlways:
+		return true
+	case LogColorAuto:
+		return isTerminal(w)
+	}
+	return false
+}
+
+// OpenLogOutput opens and returns the configured destinations of log records as a
+// single [io.WriteCloser].
+//
+// When multiple destinations are configured, every write is mirrored to all of
+// them. Closing the returned writer closes the files opened by it, while the
+// standard streams are left open.
+//
+// Custom destinations are opened as files rotated according to the configured
+// limits (see [EnvLogFileMaxSizeMB], [EnvLogFileMaxBackups], and
+// [EnvLogFileMaxAgeDays]).
+//
+// If a destination cannot be opened and [WithLogOutputFallback] was given, a
+// warning is written to the fallback, which is used in its place.
+func (c *Config) OpenLogOutput() (io.WriteCloser, error) {
+	return c.openLogOutputs(c.logOutputs)
+}
+
+// openLogOutputs opens the destinations of log records outputs as a single
+// [io.WriteCloser], as done by [Config.OpenLogOutput].
+func (c *Config) openLogOutputs(outputs []LogOutput) (io.WriteCloser, error) {
+	if len(outputs) == 1 {
+		return c.openLogOutput(outputs[0])
+	}
+	m := &multiWriteCloser{}
+	ws := make([]io.Writer, 0, len(outputs))
+	for _, output := range outputs {
+		w, err := c.openLogOutput(output)
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		ws = append(ws, w)
+		m.closers = append(m.closers, w)
+	}
+	m.Writer = io.MultiWriter(ws...)
+	return m, nil
+}
+
+func (c *Config) openLogOutput(output LogOutput) (io.WriteCloser, error) {
+	w, err := c.openLogDestination(output)
+	if err == nil || c.logOutputFallback == "" {
+		return w, err
+	}
+	fallback, fallbackErr := c.openLogDestination(c.logOutputFallback)
+	if fallbackErr != nil {
+		return nil, errors.Join(err, fallbackErr)
+	}
+	slog.New(slog.NewTextHandler(fallback, nil)).Warn(
+		"failed to open log output, using fallback",
+		"output", output,
+		"fallback", c.logOutputFallback,
+		"error", err,
+	)
+	return fallback, nil
+}
+
+func (c *Config) openLogDestination(output LogOutput) (io.WriteCloser, error) {
+	switch output {
+	case LogOutputStdout:
+		return nopWriteCloser{os.Stdout}, nil
+	case LogOutputStderr:
+		return nopWriteCloser{os.Stderr}, nil
+	case LogOutputDiscard:
+		return nopWriteCloser{io.Discard}, nil
+	case LogOutputSyslog:
+		return c.openSyslog("")
+	}
+	if addr, ok := strings.CutPrefix(string(output), logOutputSyslogPrefix); ok {
+		return c.openSyslog(addr)
+	}
+	output = c.resolveLogOutput(output)
+	f, err := openRotatingFile(string(output), rotatingFileLimits{
+		maxSize:    int64(c.logFileMaxSizeMB) * megabyte,
+		maxBackups: c.logFileMaxBackups,
+		maxAge:     time.Duration(c.logFileMaxAgeDays) * day,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log output (%s): %w", output, err)
+	}
+	return f, nil
+}
+
+type (
+	replaceAttrFunc func(groups []string, a slog.Attr) slog.Attr
+)
+
+// chainReplaceAttrs returns a function applying the given functions in order, or
+// nil when there are none.
+func chainReplaceAttrs(fns []replaceAttrFunc) replaceAttrFunc {
+	if len(fns) == 0 {
+		return nil
+	}
+	return func(groups []string, a slog.Attr) slog.Attr {
+		for _, fn := range fns {
+			a = fn(groups, a)
+		}
+		return a
+	}
+}
+
+// timeReplaceAttr rewrites the time of log records according to the configured
+// time zone and format.
+func (c *Config) timeReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 || a.Key != slog.TimeKey || a.Value.Kind() != slog.KindTime {
+		return a
+	}
+	t := a.Value.Time()
+	if c.logTimeUTC {
+		t = t.UTC()
+	}
+	switch c.logTimeFormat {
+	case "":
+		a.Value = slog.TimeValue(t)
+	case logTimeFormatUnix:
+		a.Value = slog.Int64Value(t.Unix())
+	case logTimeFormatRFC3339:
+		a.Value = slog.StringValue(t.Format(time.RFC3339))
+	default:
+		a.Value = slog.StringValue(t.Format(c.logTimeFormat))
+	}
+	return a
+}
+
+// logfmtReplaceAttr rewrites the built-in attributes of the text handler to follow
+// the logfmt conventions: the time is keyed as "ts" and the level is lowercase.
+func logfmtReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) > 0 {
+		return a
+	}
+	switch a.Key {
+	case slog.TimeKey:
+		a.Key = logfmtTimeKey
+	case slog.LevelKey:
+		a.Value = slog.StringValue(strings.ToLower(a.Value.String()))
+	}
+	return a
+}
+
+// isTerminal returns whether w writes to a terminal.
+func isTerminal(w io.Writer) bool {
+	switch w := w.(type) {
+	case nopWriteCloser:
+		return isTerminal(w.Writer)
+	case *os.File:
+		return term.IsTerminal(int(w.Fd()))
+	}
+	return false
+}
+
+type (
+	// colorWriter colorizes the level of the log records written by a text handler
+	// with ANSI escape codes, which the handler itself would quote.
+	colorWriter struct {
+		w io.Writer
+	}
+)
+
+func (cw colorWriter) Write(p []byte) (int, error) {
+	const key = slog.LevelKey + "="
+	i := bytes.Index(p, []byte(key))
+	if i == -1 {
+		return cw.w.Write(p)
+	}
+	start := i + len(key)
+	end := bytes.IndexByte(p[start:], ' ')
+	if end == -1 {
+		end = len(p) - start
+	}
+	end += start
+	level := p[start:end]
+	code := ansiLevelColor(string(level))
+	b := make([]byte, 0, len(p)+len(code)+len(ansiReset))
+	b = append(b, p[:start]...)
+	b = append(b, code...)
+	b = append(b, level...)
+	b = append(b, ansiReset...)
+	b = append(b, p[end:]...)
+	if _, err := cw.w.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+	ansiGray   = "\x1b[90m"
+)
+
+// ansiLevelColor returns the ANSI escape code of the color of the level rendered by
+// [slog.Level.String] (e.g., "INFO", "DEBUG-4").
+func ansiLevelColor(level string) string {
+	switch {
+	case strings.HasPrefix(level, "ERROR"):
+		return ansiRed
+	case strings.HasPrefix(level, "WARN"):
+		return ansiYellow
+	case strings.HasPrefix(level, "INFO"):
+		return ansiGreen
+	case level == "DEBUG":
+		return ansiBlue
+	}
+	return ansiGray
+}
+
+type (
+	nopWriteCloser struct {
+		io.Writer
+	}
+)
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+type (
+	multiWriteCloser struct {
+		io.Writer
+		closers []io.Closer
+	}
+)
+
+func (m *multiWriteCloser) Close() error {
+	errs := make([]error, 0, len(m.closers))
+	for _, c := range m.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
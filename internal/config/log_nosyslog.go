@@ -0,0 +1,19 @@
+//go:build windows || plan9 || js || wasip1
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+)
+
+const (
+	// syslogSupported defines whether [LogOutputSyslog] is supported on the platform.
+	syslogSupported = false
+)
+
+// openSyslog fails, as syslog is not supported on the platform.
+func (c *Config) openSyslog(addr string) (io.WriteCloser, error) {
+	return nil, fmt.Errorf("failed to open log output (%s): not supported on %s", LogOutputSyslog, runtime.GOOS)
+}
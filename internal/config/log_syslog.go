@@ -0,0 +1,28 @@
+//go:build !windows && !plan9 && !js && !wasip1
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"log/syslog"
+)
+
+const (
+	// syslogSupported defines whether [LogOutputSyslog] is supported on the platform.
+	syslogSupported = true
+)
+
+// openSyslog opens a connection to the syslog daemon at addr over UDP, or to the
+// local syslog daemon if addr is empty, writing with the configured tag.
+func (c *Config) openSyslog(addr string) (io.WriteCloser, error) {
+	network := ""
+	if addr != "" {
+		network = "udp"
+	}
+	w, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, c.logSyslogTag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log output (%s): %w", LogOutputSyslog, err)
+	}
+	return w, nil
+}
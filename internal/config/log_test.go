@@ -0,0 +1,603 @@
+package config_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"mega/internal/config"
+)
+
+// logRecords loads the configuration from env with the log output redirected to a
+// file, passes every record to the log handler, and returns the lines written.
+func logRecords(t *testing.T, env map[string]string, records ...slog.Record) []string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.log")
+	env[config.EnvLogOutput] = path
+	cfg, err := config.LoadFromMap(env)
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	h, closer, err := cfg.LogHandler()
+	if err != nil {
+		t.Fatalf("LogHandler() error = %v", err)
+	}
+	for _, r := range records {
+		if !h.Enabled(context.Background(), r.Level) {
+			continue
+		}
+		if err := h.Handle(context.Background(), r); err != nil {
+			t.Fatalf("Handle() error = %v", err)
+		}
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+// sampleRecord returns a record logged at a fixed time, with a string and an int
+// attribute.
+func sampleRecord(level slog.Level, msg string) slog.Record {
+	r := slog.NewRecord(time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC), level, msg, 0)
+	r.AddAttrs(slog.String("user", "jane doe"), slog.Int("attempt", 2))
+	return r
+}
+
+func TestLogFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{
+			name:   "text",
+			format: "text",
+			want:   `time=2024-05-01T12:30:00.000Z level=INFO msg="user signed in" user="jane doe" attempt=2`,
+		},
+		{
+			name:   "logfmt",
+			format: "logfmt",
+			want:   `ts=2024-05-01T12:30:00.000Z level=info msg="user signed in" user="jane doe" attempt=2`,
+		},
+		{
+			name:   "json",
+			format: "json",
+			want:   `{"time":"2024-05-01T12:30:00Z","level":"INFO","msg":"user signed in","user":"jane doe","attempt":2}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{config.EnvLogFormat: tt.format}
+			got := logRecords(t, env, sampleRecord(slog.LevelInfo, "user signed in"))
+			if len(got) != 1 || got[0] != tt.want {
+				t.Errorf("log lines = %q, want [%q]", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithLogOutputFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback bool
+		wantErr  bool
+	}{
+		{name: "without fallback", wantErr: true},
+		{name: "with fallback", fallback: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			fallback := filepath.Join(dir, "fallback.log")
+			var opts []config.Option
+			if tt.fallback {
+				opts = append(opts, config.WithLogOutputFallback(config.LogOutput(fallback)))
+			}
+			cfg, err := config.LoadFromMap(map[string]string{
+				config.EnvLogOutput: filepath.Join(dir, "missing", "app.log"),
+			}, opts...)
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			w, err := cfg.OpenLogOutput()
+			if tt.wantErr {
+				if err == nil {
+					w.Close()
+					t.Fatal("OpenLogOutput() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("OpenLogOutput() error = %v", err)
+			}
+			if _, err := io.WriteString(w, "after fallback\n"); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			data, err := os.ReadFile(fallback)
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			if len(lines) != 2 ||
+				!strings.Contains(lines[0], `level=WARN msg="failed to open log output, using fallback"`) ||
+				lines[1] != "after fallback" {
+				t.Errorf("fallback lines = %q, want the warning followed by the write", lines)
+			}
+		})
+	}
+}
+
+func TestLogOutputDiscard(t *testing.T) {
+	tests := []struct {
+		name       string
+		output     string
+		wantStream bool
+		wantFile   string
+	}{
+		{name: "discard", output: "discard"},
+		{name: "stdout", output: "stdout", wantStream: true},
+		{name: "file named discard", output: "./discard", wantFile: "discard"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Chdir(dir)
+			// The standard streams are replaced by a file counting the bytes
+			// written to them, as they are looked up when the output is opened.
+			streams, err := os.CreateTemp(t.TempDir(), "streams")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer streams.Close()
+			stdout, stderr := os.Stdout, os.Stderr
+			os.Stdout, os.Stderr = streams, streams
+			defer func() {
+				os.Stdout, os.Stderr = stdout, stderr
+			}()
+			cfg, err := config.LoadFromMap(map[string]string{config.EnvLogOutput: tt.output})
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			h, closer, err := cfg.LogHandler()
+			if err != nil {
+				t.Fatalf("LogHandler() error = %v", err)
+			}
+			slog.New(h).Info("discarded", "attempt", 1)
+			if err := closer.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			info, err := streams.Stat()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if gotStream := info.Size() > 0; gotStream != tt.wantStream {
+				t.Errorf("wrote %d bytes to the standard streams, want some written %t", info.Size(), tt.wantStream)
+			}
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var files []string
+			for _, e := range entries {
+				files = append(files, e.Name())
+			}
+			if got := strings.Join(files, ","); got != tt.wantFile {
+				t.Errorf("created files %q, want %q", got, tt.wantFile)
+			}
+		})
+	}
+}
+
+func TestLogSampling(t *testing.T) {
+	tests := []struct {
+		name       string
+		initial    string
+		thereafter string
+		level      slog.Level
+		msgs       []string
+		want       int
+	}{
+		{name: "disabled", initial: "0", thereafter: "0", level: slog.LevelInfo, msgs: []string{"a"}, want: 100},
+		{name: "initial only", initial: "10", thereafter: "0", level: slog.LevelInfo, msgs: []string{"a"}, want: 10},
+		{name: "initial and thereafter", initial: "10", thereafter: "10", level: slog.LevelInfo, msgs: []string{"a"}, want: 19},
+		{name: "per message", initial: "10", thereafter: "10", level: slog.LevelDebug, msgs: []string{"a", "b"}, want: 38},
+		{name: "warn never sampled", initial: "10", thereafter: "10", level: slog.LevelWarn, msgs: []string{"a"}, want: 100},
+		{name: "error never sampled", initial: "1", thereafter: "0", level: slog.LevelError, msgs: []string{"a"}, want: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var records []slog.Record
+			for range 100 {
+				for _, msg := range tt.msgs {
+					records = append(records, sampleRecord(tt.level, msg))
+				}
+			}
+			env := map[string]string{
+				config.EnvLogLevel:            "debug",
+				config.EnvLogSampleInitial:    tt.initial,
+				config.EnvLogSampleThereafter: tt.thereafter,
+			}
+			if got := len(logRecords(t, env, records...)); got != tt.want {
+				t.Errorf("logged %d records, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadLogSamplingInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+	}{
+		{name: "negative initial", key: config.EnvLogSampleInitial},
+		{name: "negative thereafter", key: config.EnvLogSampleThereafter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.LoadFromMap(map[string]string{tt.key: "-1"})
+			if !hasFieldError(err, tt.key) {
+				t.Errorf("LoadFromMap() error = %v, want a %s field error", err, tt.key)
+			}
+		})
+	}
+}
+
+func TestWithEmptyOutputFallsBack(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         map[string]string
+		fallsBack   bool
+		wantOutputs []config.LogOutput
+		wantErr     bool
+	}{
+		{
+			name:        "unset",
+			env:         map[string]string{},
+			wantOutputs: []config.LogOutput{config.DefaultLogOutput},
+		},
+		{
+			name:        "unset with option",
+			env:         map[string]string{},
+			fallsBack:   true,
+			wantOutputs: []config.LogOutput{config.DefaultLogOutput},
+		},
+		{
+			name:    "empty",
+			env:     map[string]string{config.EnvLogOutput: ""},
+			wantErr: true,
+		},
+		{
+			name:    "whitespace",
+			env:     map[string]string{config.EnvLogOutput: " \t"},
+			wantErr: true,
+		},
+		{
+			name:        "empty with option",
+			env:         map[string]string{config.EnvLogOutput: ""},
+			fallsBack:   true,
+			wantOutputs: []config.LogOutput{config.DefaultLogOutput},
+		},
+		{
+			name:        "whitespace with option",
+			env:         map[string]string{config.EnvLogOutput: " \t"},
+			fallsBack:   true,
+			wantOutputs: []config.LogOutput{config.DefaultLogOutput},
+		},
+		{
+			name:        "set with option",
+			env:         map[string]string{config.EnvLogOutput: "stderr"},
+			fallsBack:   true,
+			wantOutputs: []config.LogOutput{config.LogOutputStderr},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []config.Option
+			if tt.fallsBack {
+				opts = append(opts, config.WithEmptyOutputFallsBack())
+			}
+			cfg, err := config.LoadFromMap(tt.env, opts...)
+			if tt.wantErr {
+				if !hasFieldError(err, config.EnvLogOutput) {
+					t.Fatalf("LoadFromMap() error = %v, want a %s field error", err, config.EnvLogOutput)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			if got := cfg.LogOutputs(); !slices.Equal(got, tt.wantOutputs) {
+				t.Errorf("LogOutputs() = %q, want %q", got, tt.wantOutputs)
+			}
+		})
+	}
+}
+
+func TestLogSampleRate(t *testing.T) {
+	const records = 10000
+	tests := []struct {
+		name      string
+		rate      string
+		level     slog.Level
+		want      float64
+		tolerance float64
+	}{
+		{name: "keep everything", rate: "1", level: slog.LevelInfo, want: 1},
+		{name: "drop everything", rate: "0", level: slog.LevelDebug, want: 0},
+		{name: "quarter", rate: "0.25", level: slog.LevelInfo, want: 0.25, tolerance: 0.03},
+		{name: "most", rate: "0.9", level: slog.LevelDebug, want: 0.9, tolerance: 0.03},
+		{name: "warn never sampled", rate: "0", level: slog.LevelWarn, want: 1},
+		{name: "error never sampled", rate: "0.1", level: slog.LevelError, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := make([]slog.Record, records)
+			for i := range rs {
+				rs[i] = sampleRecord(tt.level, "sampled")
+			}
+			env := map[string]string{
+				config.EnvLogLevel:      "debug",
+				config.EnvLogSampleRate: tt.rate,
+			}
+			kept := float64(len(logRecords(t, env, rs...))) / records
+			if math.Abs(kept-tt.want) > tt.tolerance {
+				t.Errorf("kept fraction = %.3f, want %.3f ± %.3f", kept, tt.want, tt.tolerance)
+			}
+		})
+	}
+}
+
+func TestLoadLogSampleRateInvalid(t *testing.T) {
+	for _, rate := range []string{"-0.1", "1.5", "half", "NaN"} {
+		t.Run(rate, func(t *testing.T) {
+			_, err := config.LoadFromMap(map[string]string{config.EnvLogSampleRate: rate})
+			if !hasFieldError(err, config.EnvLogSampleRate) {
+				t.Errorf("LoadFromMap() error = %v, want a %s field error", err, config.EnvLogSampleRate)
+			}
+		})
+	}
+}
+
+func TestConfigLevelFor(t *testing.T) {
+	cfg, err := config.LoadFromMap(map[string]string{
+		config.EnvLogLevel:        "info",
+		config.EnvLogLevelsByName: "db=debug, http = error",
+	})
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		logger string
+		want   config.LogLevel
+	}{
+		{name: "override below root", logger: "db", want: config.LogLevelDebug},
+		{name: "override above root", logger: "http", want: config.LogLevelError},
+		{name: "fallback", logger: "cache", want: config.LogLevelInfo},
+		{name: "fallback for unnamed", logger: "", want: config.LogLevelInfo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cfg.LevelFor(tt.logger); got != tt.want {
+				t.Errorf("LevelFor(%q) = %q, want %q", tt.logger, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogHandlerLevelsByName(t *testing.T) {
+	env := map[string]string{
+		config.EnvLogLevel:        "info",
+		config.EnvLogLevelsByName: "db=debug,http=error",
+	}
+	path := filepath.Join(t.TempDir(), "app.log")
+	env[config.EnvLogOutput] = path
+	cfg, err := config.LoadFromMap(env)
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	h, closer, err := cfg.LogHandler()
+	if err != nil {
+		t.Fatalf("LogHandler() error = %v", err)
+	}
+	root := slog.New(h)
+	records := []struct {
+		logger *slog.Logger
+		level  slog.Level
+		msg    string
+	}{
+		{logger: root.With("logger", "db"), level: slog.LevelDebug, msg: "db debug"},
+		{logger: root.With("logger", "http"), level: slog.LevelWarn, msg: "http warn"},
+		{logger: root.With("logger", "http"), level: slog.LevelError, msg: "http error"},
+		{logger: root.With("logger", "cache"), level: slog.LevelDebug, msg: "cache debug"},
+		{logger: root.With("logger", "cache"), level: slog.LevelInfo, msg: "cache info"},
+		{logger: root, level: slog.LevelDebug, msg: "root debug"},
+		{logger: root, level: slog.LevelInfo, msg: "root info"},
+	}
+	for _, r := range records {
+		r.logger.Log(context.Background(), r.level, r.msg)
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for line := range strings.Lines(string(data)) {
+		_, msg, _ := strings.Cut(line, `msg="`)
+		msg, _, _ = strings.Cut(msg, `"`)
+		got = append(got, msg)
+	}
+	want := []string{"db debug", "http error", "cache info", "root info"}
+	if !slices.Equal(got, want) {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
+
+func TestLoadLogLevelsByNameInvalid(t *testing.T) {
+	_, err := config.LoadFromMap(map[string]string{
+		config.EnvLogLevelsByName: "db=verbose,http,cache=debug,=info",
+	})
+	var values []string
+	for _, fe := range fieldErrors(err) {
+		if fe.EnvVar == config.EnvLogLevelsByName {
+			values = append(values, fe.Value)
+		}
+	}
+	if want := []string{"db=verbose", "http", "=info"}; !slices.Equal(values, want) {
+		t.Errorf("LoadFromMap() error = %v, want field errors for %q", err, want)
+	}
+}
+
+func TestLogOutputStreamsAndPaths(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	tests := []struct {
+		name     string
+		output   string
+		want     config.LogOutput
+		wantFile string
+	}{
+		{name: "stdout", output: "stdout", want: config.LogOutputStdout},
+		{name: "stderr with whitespace", output: " stderr ", want: config.LogOutputStderr},
+		{name: "absolute path ending with stdout", output: filepath.Join(dir, "stdout"), want: config.LogOutput(filepath.Join(dir, "stdout")), wantFile: "stdout"},
+		{name: "uppercase stdout", output: "STDOUT", want: "STDOUT", wantFile: "STDOUT"},
+		{name: "relative path starting with stderr", output: "./stderr.log", want: "./stderr.log", wantFile: "stderr.log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(map[string]string{config.EnvLogOutput: tt.output})
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			if got := cfg.LogOutput(); got != tt.want {
+				t.Errorf("LogOutput() = %q, want %q", got, tt.want)
+			}
+			if tt.wantFile == "" {
+				return
+			}
+			w, err := cfg.OpenLogOutput()
+			if err != nil {
+				t.Fatalf("OpenLogOutput() error = %v", err)
+			}
+			if _, err := io.WriteString(w, "to a file\n"); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			data, err := os.ReadFile(filepath.Join(dir, tt.wantFile))
+			if err != nil || string(data) != "to a file\n" {
+				t.Errorf("file %s = %q, %v, want the record written", tt.wantFile, data, err)
+			}
+		})
+	}
+}
+
+func TestLogHandlerSecondaryOutput(t *testing.T) {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, "primary.log")
+	secondary := filepath.Join(dir, "secondary.log")
+	cfg, err := config.LoadFromMap(map[string]string{
+		config.EnvLogLevel:           "info",
+		config.EnvLogFormat:          "logfmt",
+		config.EnvLogOutput:          primary,
+		config.EnvLogSecondaryFormat: "json",
+		config.EnvLogSecondaryOutput: secondary,
+		config.EnvLogTimeUTC:         "true",
+	})
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	h, closer, err := cfg.LogHandler()
+	if err != nil {
+		t.Fatalf("LogHandler() error = %v", err)
+	}
+	for _, r := range []slog.Record{
+		sampleRecord(slog.LevelInfo, "user signed in"),
+		sampleRecord(slog.LevelDebug, "filtered by the shared level"),
+	} {
+		if h.Enabled(context.Background(), r.Level) {
+			if err := h.Handle(context.Background(), r); err != nil {
+				t.Fatalf("Handle() error = %v", err)
+			}
+		}
+	}
+	if err := closer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "primary",
+			path: primary,
+			want: `ts=2024-05-01T12:30:00.000Z level=info msg="user signed in" user="jane doe" attempt=2` + "\n",
+		},
+		{
+			name: "secondary",
+			path: secondary,
+			want: `{"time":"2024-05-01T12:30:00Z","level":"INFO","msg":"user signed in","user":"jane doe","attempt":2}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := os.ReadFile(tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("%s output = %q, want %q", tt.name, data, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadLogSecondaryInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		key  string
+	}{
+		{
+			name: "format",
+			env:  map[string]string{config.EnvLogSecondaryOutput: "stderr", config.EnvLogSecondaryFormat: "xml"},
+			key:  config.EnvLogSecondaryFormat,
+		},
+		{
+			name: "empty output in list",
+			env:  map[string]string{config.EnvLogSecondaryOutput: "stderr,,stdout"},
+			key:  config.EnvLogSecondaryOutput,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := config.LoadFromMap(tt.env)
+			if !hasFieldError(err, tt.key) {
+				t.Errorf("LoadFromMap() error = %v, want a %s field error", err, tt.key)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// Merge returns a new [Config] layering override over c: each non-zero field of
+// override replaces the corresponding field of c, while each zero field inherits
+// it from c. Neither c nor override is modified.
+//
+// A field is zero when it is:
+//
+//   - an empty string, for the log level, format, secondary format, time format,
+//     syslog tag, and color, for the server address, pprof path prefix, and TLS
+//     files, and for the metrics address and path
+//   - an empty list, for the log levels by name, outputs, secondary outputs, and
+//     default attributes, and the server trusted proxies
+//   - 0, for the numeric settings (e.g., the log file limits, the server timeouts,
+//     and the server maximum header bytes and connections)
+//   - false, for the boolean settings (e.g., the log add source and time UTC, and
+//     the server access log, HTTP/2, and keep-alive)
+//
+// Consequently, override cannot reset a numeric setting to 0 or a boolean setting
+// to false. The server address carries its network along, and the TLS files are
+// replaced together when either is set. The options (e.g., [WithLogOutputFallback])
+// and the source path follow the same rules, the keys redacted by both are, the
+// maximum durations of override (see [WithMaxDuration]) replace those of c, the
+// [Config.Sources] of override other than [SourceDefault] replace those of c, and
+// the warnings of both are kept.
+//
+// The result is not validated, see [Config.Validate].
+func (c *Config) Merge(override *Config) *Config {
+	merged := c.Clone()
+	merged.logLevel = mergeField(c.logLevel, override.logLevel)
+	if len(override.logLevelsByName) > 0 {
+		merged.logLevelsByName = maps.Clone(override.logLevelsByName)
+	}
+	merged.logFormat = mergeField(c.logFormat, override.logFormat)
+	if len(override.logOutputs) > 0 {
+		merged.logOutputs = slices.Clone(override.logOutputs)
+	}
+	if len(override.logSecondaryOutputs) > 0 {
+		merged.logSecondaryOutputs = slices.Clone(override.logSecondaryOutputs)
+	}
+	merged.logSecondaryFormat = mergeField(c.logSecondaryFormat, override.logSecondaryFormat)
+	merged.logFileMaxSizeMB = mergeField(c.logFileMaxSizeMB, override.logFileMaxSizeMB)
+	merged.logFileMaxBackups = mergeField(c.logFileMaxBackups, override.logFileMaxBackups)
+	merged.logFileMaxAgeDays = mergeField(c.logFileMaxAgeDays, override.logFileMaxAgeDays)
+	merged.logAddSource = mergeField(c.logAddSource, override.logAddSource)
+	merged.logTimeFormat = mergeField(c.logTimeFormat, override.logTimeFormat)
+	merged.logTimeUTC = mergeField(c.logTimeUTC, override.logTimeUTC)
+	merged.logColor = mergeField(c.logColor, override.logColor)
+	merged.logSyslogTag = mergeField(c.logSyslogTag, override.logSyslogTag)
+	merged.logSampleInitial = mergeField(c.logSampleInitial, override.logSampleInitial)
+	merged.logSampleThereafter = mergeField(c.logSampleThereafter, override.logSampleThereafter)
+	merged.logSampleRate = mergeField(c.logSampleRate, override.logSampleRate)
+	if len(override.logDefaultAttrs) > 0 {
+		merged.logDefaultAttrs = slices.Clone(override.logDefaultAttrs)
+	}
+	if override.serverAddress != "" {
+		merged.serverNetwork = override.serverNetwork
+		merged.serverAddress = override.serverAddress
+	}
+	merged.serverReadTimeout = mergeField(c.serverReadTimeout, override.serverReadTimeout)
+	merged.serverReadHeaderTimeout = mergeField(c.serverReadHeaderTimeout, override.serverReadHeaderTimeout)
+	merged.serverWriteTimeout = mergeField(c.serverWriteTimeout, override.serverWriteTimeout)
+	merged.serverIdleTimeout = mergeField(c.serverIdleTimeout, override.serverIdleTimeout)
+	merged.serverRequestTimeout = mergeField(c.serverRequestTimeout, override.serverRequestTimeout)
+	merged.serverShutdownTimeout = mergeField(c.serverShutdownTimeout, override.serverShutdownTimeout)
+	merged.serverShutdownGrace = mergeField(c.serverShutdownGrace, override.serverShutdownGrace)
+	merged.serverMaxHeaderBytes = mergeField(c.serverMaxHeaderBytes, override.serverMaxHeaderBytes)
+	merged.serverMaxConns = mergeField(c.serverMaxConns, override.serverMaxConns)
+	merged.serverAccessLog = mergeField(c.serverAccessLog, override.serverAccessLog)
+	merged.serverHTTP2 = mergeField(c.serverHTTP2, override.serverHTTP2)
+	merged.serverKeepAlive = mergeField(c.serverKeepAlive, override.serverKeepAlive)
+	merged.serverPprof = mergeField(c.serverPprof, override.serverPprof)
+	merged.serverPprofPathPrefix = mergeField(c.serverPprofPathPrefix, override.serverPprofPathPrefix)
+	if len(override.serverTrustedProxies) > 0 {
+		merged.serverTrustedProxies = slices.Clone(override.serverTrustedProxies)
+	}
+	if override.serverTLSCertFile != "" || override.serverTLSKeyFile != "" {
+		merged.serverTLSCertFile = override.serverTLSCertFile
+		merged.serverTLSKeyFile = override.serverTLSKeyFile
+	}
+	merged.metricsAddress = mergeField(c.metricsAddress, override.metricsAddress)
+	merged.metricsPath = mergeField(c.metricsPath, override.metricsPath)
+	merged.logOutputFallback = mergeField(c.logOutputFallback, override.logOutputFallback)
+	merged.logOutputBaseDir = mergeField(c.logOutputBaseDir, override.logOutputBaseDir)
+	merged.confinedLogOutput = mergeField(c.confinedLogOutput, override.confinedLogOutput)
+	if override.onShutdown != nil {
+		merged.onShutdown = override.onShutdown
+	}
+	merged.redactedKeys = slices.Concat(c.redactedKeys, override.redactedKeys)
+	if merged.maxDurations == nil && len(override.maxDurations) > 0 {
+		merged.maxDurations = make(map[string]time.Duration)
+	}
+	maps.Copy(merged.maxDurations, override.maxDurations)
+	merged.sourcePath = mergeField(c.sourcePath, override.sourcePath)
+	for key, source := range override.sources {
+		if source != SourceDefault {
+			if merged.sources == nil {
+				merged.sources = make(map[string]string)
+			}
+			merged.sources[key] = source
+		}
+	}
+	merged.warnings = slices.Concat(c.warnings, override.warnings)
+	return merged
+}
+
+// mergeField returns override, or base if override is the zero value.
+func mergeField[T comparable](base, override T) T {
+	var zero T
+	if override == zero {
+		return base
+	}
+	return override
+}
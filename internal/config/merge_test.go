@@ -0,0 +1,105 @@
+package config
+
+import (
+	"maps"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestConfigMerge(t *testing.T) {
+	base, err := LoadFromMap(map[string]string{
+		EnvLogLevel:             "info",
+		EnvLogFormat:            "json",
+		EnvLogOutput:            "stderr",
+		EnvServerAddress:        "localhost:8080",
+		EnvServerReadTimeout:    "5s",
+		EnvServerTrustedProxies: "10.0.0.0/8",
+		EnvServerAccessLog:      "true",
+	})
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	tests := []struct {
+		name     string
+		override *Config
+		want     map[string]string
+	}{
+		{
+			name:     "empty override",
+			override: &Config{},
+			want:     map[string]string{},
+		},
+		{
+			name: "strings",
+			override: &Config{
+				logLevel:      LogLevelDebug,
+				logSyslogTag:  "app",
+				serverNetwork: ServerNetworkUnix,
+				serverAddress: "/run/app.sock",
+			},
+			want: map[string]string{
+				EnvLogLevel:      "debug",
+				EnvLogSyslogTag:  "app",
+				EnvServerAddress: "unix:///run/app.sock",
+			},
+		},
+		{
+			name: "durations and numbers",
+			override: &Config{
+				serverReadTimeout: time.Second,
+				serverMaxConns:    10,
+			},
+			want: map[string]string{
+				EnvServerReadTimeout: "1s",
+				EnvServerMaxConns:    "10",
+			},
+		},
+		{
+			name: "lists",
+			override: &Config{
+				logOutputs:           []LogOutput{LogOutputStdout, LogOutputDiscard},
+				serverTrustedProxies: []netip.Prefix{netip.MustParsePrefix("192.168.0.0/16")},
+			},
+			want: map[string]string{
+				EnvLogOutput:            "stdout,discard",
+				EnvServerTrustedProxies: "192.168.0.0/16",
+			},
+		},
+		{
+			name: "booleans",
+			override: &Config{
+				logAddSource:    true,
+				serverAccessLog: false,
+			},
+			want: map[string]string{
+				EnvLogAddSource: "true",
+			},
+		},
+		{
+			name: "TLS files together",
+			override: &Config{
+				serverTLSCertFile: "/etc/tls/cert.pem",
+			},
+			want: map[string]string{
+				EnvServerTLSCertFile: "/etc/tls/cert.pem",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := base.env()
+			want := base.env()
+			maps.Copy(want, tt.want)
+			got := base.Merge(tt.override).env()
+			for key := range want {
+				if got[key] != want[key] {
+					t.Errorf("Merge() %s = %q, want %q", key, got[key], want[key])
+				}
+			}
+			if !maps.Equal(base.env(), before) {
+				t.Error("Merge() modified the base configuration")
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+type (
+	// Option configures how a [Config] is loaded and how it behaves once loaded.
+	Option func(*options)
+
+	options struct {
+		logOutputFallback    LogOutput
+		strict               bool
+		onShutdown           func()
+		emptyOutputFallsBack bool
+		redactedKeys         []string
+		observer             func(event string, d time.Duration)
+		remoteSource         func(ctx context.Context) (map[string]string, error)
+		remoteOptional       bool
+		bindCheck            bool
+		failFast             bool
+		defaultDurationUnit  time.Duration
+		validators           []func(*Config) error
+		certExpiryCheck      bool
+		certMinRemaining     time.Duration
+		debounce             *time.Duration
+		noEnvOverride        bool
+		maxDurations         map[string]time.Duration
+		reloadLogger         *slog.Logger
+		logOutputBaseDir     string
+		confinedLogOutput    bool
+	}
+)
+
+var (
+	// durationUnits defines the units accepted by [WithDefaultDurationUnit].
+	durationUnits = []time.Duration{time.Millisecond, time.Second, time.Minute}
+)
+
+const (
+	// DefaultMaxDuration defines the maximum of the duration settings (e.g.,
+	// [EnvServerWriteTimeout]), unless configured otherwise with [WithMaxDuration],
+	// so that a mistyped unit (e.g., "10000h") is reported rather than used.
+	DefaultMaxDuration = 24 * time.Hour
+)
+
+const (
+	// LoadPhaseReadFile identifies the reading and parsing of a configuration file,
+	// reported to the function configured with [WithObserver].
+	LoadPhaseReadFile = "read-file"
+
+	// LoadPhaseReadRemote identifies the reading of the remote source configured
+	// with [WithRemoteSource], reported to the function configured with
+	// [WithObserver].
+	LoadPhaseReadRemote = "read-remote"
+
+	// LoadPhaseParseEnv identifies the parsing of the settings from their sources,
+	// reported to the function configured with [WithObserver].
+	LoadPhaseParseEnv = "parse-env"
+
+	// LoadPhaseValidate identifies the validation of the constraints spanning
+	// multiple settings, reported to the function configured with [WithObserver].
+	LoadPhaseValidate = "validate"
+)
+
+// WithLogOutputFallback configures the destination stream used instead of a custom
+// log output (typically a file path) that cannot be opened.
+//
+// When set, [Config.OpenLogOutput] and [Config.LogHandler] write a warning to the
+// fallback and keep using it instead of returning an error. This favors keeping
+// the application running over surfacing the misconfiguration, which may then go
+// unnoticed until the warning is read.
+//
+// If output is empty, [LogOutputStderr] is used.
+func WithLogOutputFallback(output LogOutput) Option {
+	if output == "" {
+		output = LogOutputStderr
+	}
+	return func(o *options) {
+		o.logOutputFallback = output
+	}
+}
+
+// WithStrict configures the loading to reject the environment variables set within
+// the configuration namespace, that is sharing the prefix of a known environment
+// variable (e.g., "LOG_" or "SERVER_"), that do not configure any setting, such as
+// the misspelled "LOG_LEVL".
+//
+// With [Check], strict mode also reports the warnings as errors.
+//
+// Strict mode is opt-in, as it fails in environments legitimately sharing the
+// namespace with other applications.
+func WithStrict() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithOnShutdown configures a function called by [Config.Serve] as soon as the
+// server starts shutting down, before the shutdown grace period, typically
+// failing the application's readiness checks so that load balancers stop routing
+// requests to the server while it drains.
+func WithOnShutdown(fn func()) Option {
+	return func(o *options) {
+		o.onShutdown = fn
+	}
+}
+
+// WithEmptyOutputFallsBack configures the loading to resolve [EnvLogOutput] to
+// [DefaultLogOutput] when it is set but empty (or blank), as when it is unset,
+// instead of reporting an error.
+func WithEmptyOutputFallsBack() Option {
+	return func(o *options) {
+		o.emptyOutputFallsBack = true
+	}
+}
+
+// WithLogOutputBaseDir configures the directory against which the relative file
+// paths of the log outputs (e.g., "logs/app.log") are resolved, instead of the
+// working directory, see [Config.ResolvedLogOutputPath].
+func WithLogOutputBaseDir(dir string) Option {
+	return func(o *options) {
+		o.logOutputBaseDir = dir
+	}
+}
+
+// WithConfinedLogOutput configures the loading to reject the file paths of the log
+// outputs escaping the directory configured with [WithLogOutputBaseDir], or the
+// working directory if none, such as "../app.log". Absolute paths are only
+// accepted within the configured directory.
+func WithConfinedLogOutput() Option {
+	return func(o *options) {
+		o.confinedLogOutput = true
+	}
+}
+
+// WithRedactedKeys configures the environment variables, named by keys, whose
+// values are sensitive, on top of the built-in ones (e.g., [EnvServerTLSKeyFile]).
+// Their values are replaced by "****" wherever the configuration is rendered, as
+// by [Config.String], [Config.WriteTo], [Config.MarshalJSON], [Config.Diff], and
+// [Config.Environ] when redacting, while their keys are kept.
+func WithRedactedKeys(keys ...string) Option {
+	return func(o *options) {
+		o.redactedKeys = append(o.redactedKeys, keys...)
+	}
+}
+
+// WithObserver configures a function called as each phase of the loading of the
+// configuration ends (e.g., [LoadPhaseParseEnv]), with the time it took, giving
+// insight into the loading latency without depending on a metrics library.
+func WithObserver(observer func(event string, d time.Duration)) Option {
+	return func(o *options) {
+		o.observer = observer
+	}
+}
+
+// WithRemoteSource configures a function supplying additional values, keyed by
+// environment variable name, typically looked up in a secrets manager or parameter
+// store, without depending on any cloud library. It is called by every function
+// loading the configuration with options (e.g., [New], [NewFromFile], [Check]),
+// with the context of [NewContext] and [CheckContext], or [context.Background]
+// otherwise.
+//
+// The remote values have the lowest precedence: they are used only for the
+// environment variables that are unset and missing from the configuration file,
+// if any. If the function fails, loading fails too, unless [WithRemoteOptional] is
+// also given.
+func WithRemoteSource(source func(ctx context.Context) (map[string]string, error)) Option {
+	return func(o *options) {
+		o.remoteSource = source
+	}
+}
+
+// WithRemoteOptional configures the failures of the remote source configured with
+// [WithRemoteSource] to be reported as warnings, see [Config.Warnings], instead of
+// failing the loading, which then proceeds without the remote values.
+func WithRemoteOptional() Option {
+	return func(o *options) {
+		o.remoteOptional = true
+	}
+}
+
+// WithBindCheck configures the loading to check that the server can listen on its
+// address, reporting an address already in use or requiring privileges as a
+// loading error instead of a failure once the server starts.
+//
+// TCP addresses are checked by listening on them and closing the listener at once,
+// so the check is racy: another process may still take the address before the
+// server starts. Addresses with an ephemeral port (e.g., ":0") are not checked, as
+// they are always bindable, and Unix domain sockets are checked by ensuring that
+// their parent directory is writable.
+func WithBindCheck() Option {
+	return func(o *options) {
+		o.bindCheck = true
+	}
+}
+
+// WithFailFast configures the loading to stop at the first invalid setting,
+// returning a single error, instead of checking every setting and returning an
+// error joining all those found.
+//
+// Failing fast gives quicker feedback when the errors are fixed one at a time, as
+// in tight loops, while collecting all errors, the default, reports every
+// misconfiguration at once.
+func WithFailFast() Option {
+	return func(o *options) {
+		o.failFast = true
+	}
+}
+
+// WithDefaultDurationUnit configures the unit of the durations set as bare integers
+// (e.g., "500"), which are seconds by default. The durations set with a unit
+// suffix (e.g., "500ms") are unaffected.
+//
+// The unit must be one of [time.Millisecond], [time.Second], or [time.Minute];
+// loading fails otherwise.
+func WithDefaultDurationUnit(unit time.Duration) Option {
+	return func(o *options) {
+		o.defaultDurationUnit = unit
+	}
+}
+
+// WithValidator configures a function checking application-specific constraints
+// on the loaded configuration (e.g., requiring [LogFormatJSON] in production),
+// after the built-in checks spanning multiple settings. It is called only if every
+// setting is valid, so that it may rely on them, and is skipped otherwise, loading
+// failing with the errors of the invalid settings alone. If it returns an error,
+// loading fails with it, joined with those of the built-in checks and of the other
+// validators.
+//
+// Multiple validators are called in the order they are given. They are not called
+// by [Config.Validate].
+func WithValidator(validator func(*Config) error) Option {
+	return func(o *options) {
+		o.validators = append(o.validators, validator)
+	}
+}
+
+// WithCertExpiryCheck configures the loading to check the server's TLS certificate,
+// when TLS is enabled, reporting a certificate expiring within minRemaining as a
+// warning, see [Config.Warnings], and an expired or unloadable one as an error, so
+// that a certificate left unrenewed is noticed before the server starts.
+func WithCertExpiryCheck(minRemaining time.Duration) Option {
+	return func(o *options) {
+		o.certExpiryCheck = true
+		o.certMinRemaining = minRemaining
+	}
+}
+
+// WithReloadDebounce configures how long [WatchFile] waits for the file to stop
+// changing before reloading it, coalescing the successive writes of a single save
+// (e.g., by editors truncating then writing the file) into a single reload. It is
+// [DefaultReloadDebounce] by default, and 0 reloads the file as soon as a change
+// is detected.
+func WithReloadDebounce(d time.Duration) Option {
+	return func(o *options) {
+		o.debounce = &d
+	}
+}
+
+// WithNoEnvOverride configures [NewFromFile] and [NewFromReader] to ignore the
+// environment variables, so that the configuration holds the values read only,
+// and the defaults of the settings left unset. The references of the values to
+// environment variables are then expanded to empty strings.
+func WithNoEnvOverride() Option {
+	return func(o *options) {
+		o.noEnvOverride = true
+	}
+}
+
+// WithMaxDuration configures the maximum of the duration setting configured by the
+// environment variable named by env (e.g., [EnvServerIdleTimeout]), overriding
+// [DefaultMaxDuration], with the longer durations reported as errors. If limit is 0
+// or negative, the setting is not bounded.
+func WithMaxDuration(env string, limit time.Duration) Option {
+	return func(o *options) {
+		if o.maxDurations == nil {
+			o.maxDurations = make(map[string]time.Duration)
+		}
+		o.maxDurations[env] = limit
+	}
+}
+
+// WithReloadLogger configures the logger of the reloads of the [Reloadable] created
+// by [NewReloadable], see [Reloadable.Reload]. The reloads are not logged by
+// default.
+func WithReloadLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.reloadLogger = logger
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// observe reports the phase named by event, started at start, to the configured
+// observer, if any.
+func (o *options) observe(event string, start time.Time) {
+	if o.observer != nil {
+		o.observer(event, time.Since(start))
+	}
+}
+
+// durationUnit returns the unit of the durations set as bare integers, seconds
+// unless a valid unit is configured.
+func (o *options) durationUnit() time.Duration {
+	if slices.Contains(durationUnits, o.defaultDurationUnit) {
+		return o.defaultDurationUnit
+	}
+	return time.Second
+}
+
+// maxDuration returns the maximum of the duration setting configured by the
+// environment variable named by key, or 0 if it is not bounded.
+func (o *options) maxDuration(key string) time.Duration {
+	limit, ok := o.maxDurations[key]
+	if !ok {
+		return DefaultMaxDuration
+	}
+	return max(limit, 0)
+}
+
+// reloadDebounce returns how long [WatchFile] waits for the file to stop changing
+// before reloading it.
+func (o *options) reloadDebounce() time.Duration {
+	if o.debounce == nil {
+		return DefaultReloadDebounce
+	}
+	return max(*o.debounce, 0)
+}
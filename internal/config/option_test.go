@@ -0,0 +1,563 @@
+package config_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"mega/internal/config"
+)
+
+// remoteSource returns a remote source supplying values, or failing with err if
+// non-nil.
+func remoteSource(values map[string]string, err error) config.Option {
+	return config.WithRemoteSource(func(context.Context) (map[string]string, error) {
+		return values, err
+	})
+}
+
+func TestWithRemoteSource(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"log_format": "json"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	remote := map[string]string{
+		config.EnvLogLevel:  "debug",
+		config.EnvLogFormat: "logfmt",
+	}
+	loaders := []struct {
+		name       string
+		load       func(opts ...config.Option) (*config.Config, error)
+		wantFormat config.LogFormat
+	}{
+		{
+			name:       "New",
+			load:       config.New,
+			wantFormat: config.LogFormatLogfmt,
+		},
+		{
+			name: "LoadFromMap",
+			load: func(opts ...config.Option) (*config.Config, error) {
+				return config.LoadFromMap(nil, opts...)
+			},
+			wantFormat: config.LogFormatLogfmt,
+		},
+		{
+			name: "NewFromFile",
+			load: func(opts ...config.Option) (*config.Config, error) {
+				return config.NewFromFile(path, opts...)
+			},
+			wantFormat: config.LogFormatJSON,
+		},
+		{
+			name: "NewFromReader",
+			load: func(opts ...config.Option) (*config.Config, error) {
+				return config.NewFromReader(strings.NewReader("LOG_FORMAT=json"), "env", opts...)
+			},
+			wantFormat: config.LogFormatJSON,
+		},
+	}
+	for _, tt := range loaders {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tt.load(remoteSource(remote, nil))
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if cfg.LogLevel() != config.LogLevelDebug {
+				t.Errorf("LogLevel() = %q, want %q", cfg.LogLevel(), config.LogLevelDebug)
+			}
+			if cfg.LogFormat() != tt.wantFormat {
+				t.Errorf("LogFormat() = %q, want %q", cfg.LogFormat(), tt.wantFormat)
+			}
+			if got := cfg.Sources()[config.EnvLogLevel]; got != config.SourceRemote {
+				t.Errorf("Sources()[%s] = %q, want %q", config.EnvLogLevel, got, config.SourceRemote)
+			}
+		})
+	}
+}
+
+func TestWithRemoteSourceEnvPrecedence(t *testing.T) {
+	t.Setenv(config.EnvLogLevel, "warn")
+	cfg, err := config.New(remoteSource(map[string]string{config.EnvLogLevel: "debug"}, nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if cfg.LogLevel() != config.LogLevelWarn {
+		t.Errorf("LogLevel() = %q, want %q", cfg.LogLevel(), config.LogLevelWarn)
+	}
+}
+
+func TestWithRemoteSourceFailure(t *testing.T) {
+	errRemote := errors.New("remote unavailable")
+	if _, err := config.New(remoteSource(nil, errRemote)); !errors.Is(err, errRemote) {
+		t.Errorf("New() error = %v, want %v", err, errRemote)
+	}
+	cfg, err := config.New(remoteSource(nil, errRemote), config.WithRemoteOptional())
+	if err != nil {
+		t.Fatalf("New() with WithRemoteOptional error = %v", err)
+	}
+	if len(cfg.Warnings()) != 1 || !strings.Contains(cfg.Warnings()[0], errRemote.Error()) {
+		t.Errorf("Warnings() = %q, want the remote source error", cfg.Warnings())
+	}
+}
+
+func TestCheckWithRemoteSource(t *testing.T) {
+	opt := remoteSource(map[string]string{config.EnvLogLevel: "bogus"}, nil)
+	if _, err := config.New(opt); err == nil {
+		t.Fatal("New() error = nil, want an invalid log level")
+	}
+	if err := config.Check(opt); err == nil {
+		t.Error("Check() error = nil, want an invalid log level")
+	}
+	if err := config.CheckContext(context.Background(), opt); err == nil {
+		t.Error("CheckContext() error = nil, want an invalid log level")
+	}
+}
+
+func TestWithObserver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"log_level": "debug"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		load       func(opts ...config.Option) (*config.Config, error)
+		wantPhases []string
+	}{
+		{
+			name: "LoadFromMap",
+			load: func(opts ...config.Option) (*config.Config, error) {
+				return config.LoadFromMap(nil, opts...)
+			},
+			wantPhases: []string{config.LoadPhaseParseEnv, config.LoadPhaseValidate},
+		},
+		{
+			name: "remote source",
+			load: func(opts ...config.Option) (*config.Config, error) {
+				return config.LoadFromMap(nil, append(opts, remoteSource(nil, nil))...)
+			},
+			wantPhases: []string{config.LoadPhaseReadRemote, config.LoadPhaseParseEnv, config.LoadPhaseValidate},
+		},
+		{
+			name: "NewFromFile",
+			load: func(opts ...config.Option) (*config.Config, error) {
+				return config.NewFromFile(path, opts...)
+			},
+			wantPhases: []string{config.LoadPhaseReadFile, config.LoadPhaseParseEnv, config.LoadPhaseValidate},
+		},
+		{
+			name: "NewFromFile with a missing file",
+			load: func(opts ...config.Option) (*config.Config, error) {
+				cfg, err := config.NewFromFile(path+".missing", opts...)
+				if err == nil {
+					t.Error("NewFromFile() error = nil, want an error")
+				}
+				return cfg, nil
+			},
+			wantPhases: []string{config.LoadPhaseReadFile},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var phases []string
+			observer := config.WithObserver(func(event string, d time.Duration) {
+				if d < 0 {
+					t.Errorf("observed %s lasting %s, want a non-negative duration", event, d)
+				}
+				phases = append(phases, event)
+			})
+			if _, err := tt.load(observer); err != nil {
+				t.Fatalf("load error = %v", err)
+			}
+			if !slices.Equal(phases, tt.wantPhases) {
+				t.Errorf("observed phases %q, want %q", phases, tt.wantPhases)
+			}
+		})
+	}
+}
+
+func TestWithDefaultDurationUnit(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []config.Option
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{name: "seconds by default", value: "500", want: 500 * time.Second},
+		{name: "milliseconds", opts: []config.Option{config.WithDefaultDurationUnit(time.Millisecond)}, value: "500", want: 500 * time.Millisecond},
+		{name: "milliseconds with whitespace", opts: []config.Option{config.WithDefaultDurationUnit(time.Millisecond)}, value: " 250 ", want: 250 * time.Millisecond},
+		{name: "milliseconds with suffix", opts: []config.Option{config.WithDefaultDurationUnit(time.Millisecond)}, value: "2s", want: 2 * time.Second},
+		{name: "minutes", opts: []config.Option{config.WithDefaultDurationUnit(time.Minute)}, value: "3", want: 3 * time.Minute},
+		{name: "invalid unit", opts: []config.Option{config.WithDefaultDurationUnit(time.Hour)}, value: "3", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(map[string]string{config.EnvServerWriteTimeout: tt.value}, tt.opts...)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadFromMap() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			if got := cfg.ServerWriteTimeout(); got != tt.want {
+				t.Errorf("ServerWriteTimeout() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithValidator(t *testing.T) {
+	errFirst := errors.New("first")
+	errSecond := errors.New("second")
+	requireJSON := func(cfg *config.Config) error {
+		if cfg.LogFormat() != config.LogFormatJSON {
+			return errFirst
+		}
+		return nil
+	}
+	tests := []struct {
+		name       string
+		env        map[string]string
+		validators []func(*config.Config) error
+		wantErrs   []error
+		wantCalled bool
+	}{
+		{
+			name:       "passing",
+			env:        map[string]string{config.EnvLogFormat: "json"},
+			validators: []func(*config.Config) error{requireJSON},
+			wantCalled: true,
+		},
+		{
+			name:       "failing",
+			env:        map[string]string{config.EnvLogFormat: "text"},
+			validators: []func(*config.Config) error{requireJSON},
+			wantErrs:   []error{errFirst},
+			wantCalled: true,
+		},
+		{
+			name: "several failing, joined",
+			env:  map[string]string{config.EnvLogFormat: "text"},
+			validators: []func(*config.Config) error{
+				requireJSON,
+				func(*config.Config) error { return errSecond },
+			},
+			wantErrs:   []error{errFirst, errSecond},
+			wantCalled: true,
+		},
+		{
+			name:       "skipped on invalid settings",
+			env:        map[string]string{config.EnvLogLevel: "bogus"},
+			validators: []func(*config.Config) error{requireJSON},
+			wantCalled: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			called := false
+			opts := []config.Option{config.WithValidator(func(*config.Config) error {
+				called = true
+				return nil
+			})}
+			for _, validator := range tt.validators {
+				opts = append(opts, config.WithValidator(validator))
+			}
+			_, err := config.LoadFromMap(tt.env, opts...)
+			if called != tt.wantCalled {
+				t.Errorf("validator called = %t, want %t", called, tt.wantCalled)
+			}
+			for _, want := range tt.wantErrs {
+				if !errors.Is(err, want) {
+					t.Errorf("LoadFromMap() error = %v, want %v", err, want)
+				}
+			}
+			if len(tt.wantErrs) == 0 && tt.wantCalled && err != nil {
+				t.Errorf("LoadFromMap() error = %v", err)
+			}
+		})
+	}
+}
+
+// writeCert writes a self-signed certificate valid until notAfter, and its private
+// key, to PEM files in a temporary directory, returning their paths.
+func writeCert(t *testing.T, notAfter time.Time) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestWithCertExpiryCheck(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		notAfter    time.Time
+		tls         bool
+		corrupt     bool
+		wantErr     bool
+		wantWarning bool
+	}{
+		{name: "TLS disabled", tls: false},
+		{name: "valid", notAfter: now.Add(90 * 24 * time.Hour), tls: true},
+		{name: "short-lived", notAfter: now.Add(time.Hour), tls: true, wantWarning: true},
+		{name: "expired", notAfter: now.Add(-time.Hour), tls: true, wantErr: true},
+		{name: "unloadable", notAfter: now.Add(90 * 24 * time.Hour), tls: true, corrupt: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := map[string]string{}
+			if tt.tls {
+				certFile, keyFile := writeCert(t, tt.notAfter)
+				if tt.corrupt {
+					if err := os.WriteFile(certFile, []byte("not a certificate"), 0o600); err != nil {
+						t.Fatal(err)
+					}
+				}
+				env[config.EnvServerTLSCertFile] = certFile
+				env[config.EnvServerTLSKeyFile] = keyFile
+			}
+			cfg, err := config.LoadFromMap(env, config.WithCertExpiryCheck(7*24*time.Hour))
+			if tt.wantErr {
+				if !hasFieldError(err, config.EnvServerTLSCertFile) {
+					t.Fatalf("LoadFromMap() error = %v, want a %s field error", err, config.EnvServerTLSCertFile)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			warnings := cfg.Warnings()
+			if gotWarning := len(warnings) > 0; gotWarning != tt.wantWarning {
+				t.Errorf("Warnings() = %q, want a warning %t", warnings, tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestWithMaxDuration(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		opts    []config.Option
+		wantErr bool
+	}{
+		{
+			name:  "at the default ceiling",
+			value: "24h",
+		},
+		{
+			name:  "just under the default ceiling",
+			value: "23h59m59s",
+		},
+		{
+			name:    "just over the default ceiling",
+			value:   "24h0m1s",
+			wantErr: true,
+		},
+		{
+			name:    "far over the default ceiling",
+			value:   "10000h",
+			wantErr: true,
+		},
+		{
+			name:  "just under a configured ceiling",
+			value: "59s",
+			opts:  []config.Option{config.WithMaxDuration(config.EnvServerIdleTimeout, time.Minute)},
+		},
+		{
+			name:    "just over a configured ceiling",
+			value:   "61s",
+			opts:    []config.Option{config.WithMaxDuration(config.EnvServerIdleTimeout, time.Minute)},
+			wantErr: true,
+		},
+		{
+			name:  "disabled ceiling",
+			value: "10000h",
+			opts:  []config.Option{config.WithMaxDuration(config.EnvServerIdleTimeout, 0)},
+		},
+		{
+			name:    "ceiling of another setting",
+			value:   "48h",
+			opts:    []config.Option{config.WithMaxDuration(config.EnvServerReadTimeout, 0)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(map[string]string{config.EnvServerIdleTimeout: tt.value}, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadFromMap() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestWithMaxDurationBuilder(t *testing.T) {
+	_, err := config.NewBuilder().SetServerIdleTimeout(48 * time.Hour).Build()
+	if err == nil {
+		t.Error("Build() error = nil, want the default ceiling exceeded")
+	}
+	cfg, err := config.NewBuilder(config.WithMaxDuration(config.EnvServerIdleTimeout, 0)).
+		SetServerIdleTimeout(48 * time.Hour).
+		Build()
+	if err != nil {
+		t.Fatalf("Build() with the ceiling disabled error = %v", err)
+	}
+	if err := cfg.SetLogLevel(config.LogLevelDebug); err != nil {
+		t.Errorf("SetLogLevel() error = %v", err)
+	}
+	if err := cfg.Clone().Validate(); err != nil {
+		t.Errorf("Clone().Validate() error = %v", err)
+	}
+}
+
+func TestWithLogOutputBaseDir(t *testing.T) {
+	base := filepath.Join(t.TempDir(), "base")
+	tests := []struct {
+		name         string
+		output       string
+		confined     bool
+		wantResolved string
+		wantErr      bool
+	}{
+		{
+			name:         "relative",
+			output:       "logs/app.log",
+			wantResolved: filepath.Join(base, "logs", "app.log"),
+		},
+		{
+			name:         "relative cleaned",
+			output:       "logs/../app.log",
+			wantResolved: filepath.Join(base, "app.log"),
+		},
+		{
+			name:         "absolute",
+			output:       filepath.Join(t.TempDir(), "app.log"),
+			wantResolved: "",
+		},
+		{
+			name:         "escaping",
+			output:       "../app.log",
+			wantResolved: filepath.Join(filepath.Dir(base), "app.log"),
+		},
+		{
+			name:         "stream",
+			output:       "stdout",
+			wantResolved: "",
+		},
+		{
+			name:         "confined relative",
+			output:       "logs/app.log",
+			confined:     true,
+			wantResolved: filepath.Join(base, "logs", "app.log"),
+		},
+		{
+			name:         "confined absolute within",
+			output:       filepath.Join(base, "app.log"),
+			confined:     true,
+			wantResolved: filepath.Join(base, "app.log"),
+		},
+		{
+			name:     "confined absolute outside",
+			output:   filepath.Join(t.TempDir(), "app.log"),
+			confined: true,
+			wantErr:  true,
+		},
+		{
+			name:     "confined escaping",
+			output:   "logs/../../app.log",
+			confined: true,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := []config.Option{config.WithLogOutputBaseDir(base)}
+			if tt.confined {
+				opts = append(opts, config.WithConfinedLogOutput())
+			}
+			cfg, err := config.LoadFromMap(map[string]string{config.EnvLogOutput: tt.output}, opts...)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadFromMap() error = %v, wantErr %t", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			want := tt.wantResolved
+			if want == "" && tt.output != "stdout" {
+				want = tt.output
+			}
+			if got := cfg.ResolvedLogOutputPath(); got != want {
+				t.Errorf("ResolvedLogOutputPath() = %q, want %q", got, want)
+			}
+			if got := string(cfg.LogOutput()); got != tt.output {
+				t.Errorf("LogOutput() = %q, want the raw %q", got, tt.output)
+			}
+		})
+	}
+}
+
+func TestWithLogOutputBaseDirBuilder(t *testing.T) {
+	base := t.TempDir()
+	cfg, err := config.NewBuilder(config.WithLogOutputBaseDir(base)).SetLogOutput("app.log").Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if got, want := cfg.ResolvedLogOutputPath(), filepath.Join(base, "app.log"); got != want {
+		t.Errorf("ResolvedLogOutputPath() = %q, want %q", got, want)
+	}
+	_, err = config.NewBuilder(config.WithLogOutputBaseDir(base), config.WithConfinedLogOutput()).
+		SetLogOutput("../app.log").
+		Build()
+	if err == nil {
+		t.Error("Build() error = nil, want the log output to escape its base directory")
+	}
+}
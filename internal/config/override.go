@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+)
+
+// SetLogLevel overrides the log level of the configuration with level, validated
+// as [EnvLogLevel] is when loading it, returning an error and leaving the
+// configuration unchanged if level is invalid.
+//
+// The Set methods are deliberate post-load overrides of a single setting, such as
+// forcing debug logging when a command-line flag is passed, while keeping the
+// others as loaded. The overridden settings are labeled [SourceOverride] in
+// [Config.Sources]. They are not safe for concurrent use: the caller must ensure
+// that the configuration is not read while it is being overridden, typically by
+// overriding it before handing it to other goroutines.
+func (c *Config) SetLogLevel(level LogLevel) error {
+	var val LogLevel
+	if err := c.override(EnvLogLevel, string(level), func(l *loader) { val = l.logLevel() }); err != nil {
+		return err
+	}
+	c.logLevel = val
+	return nil
+}
+
+// SetLogFormat overrides the log format of the configuration with format,
+// validated as [EnvLogFormat] is when loading it, returning an error and leaving
+// the configuration unchanged if format is invalid. See [Config.SetLogLevel].
+func (c *Config) SetLogFormat(format LogFormat) error {
+	var val LogFormat
+	if err := c.override(EnvLogFormat, string(format), func(l *loader) { val = l.logFormat() }); err != nil {
+		return err
+	}
+	c.logFormat = val
+	return nil
+}
+
+// override validates raw as the value of the environment variable named by key,
+// with load, the loader method of the setting, and on success labels the setting
+// as overridden, if the configuration was loaded.
+func (c *Config) override(key, raw string, load func(l *loader)) error {
+	l := newLoader(mapLookupEnv(map[string]string{key: raw}), nil, nil)
+	l.opts = c.validationOptions()
+	load(l)
+	if err := l.Err(); err != nil {
+		return fmt.Errorf("invalid override: %w", err)
+	}
+	if c.sources != nil {
+		c.sources[key] = SourceOverride
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package config_test
+
+import (
+	"strings"
+	"testing"
+
+	"mega/internal/config"
+)
+
+func TestConfigSetLogLevel(t *testing.T) {
+	tests := []struct {
+		name       string
+		level      config.LogLevel
+		want       config.LogLevel
+		wantSource string
+		wantErr    bool
+	}{
+		{name: "valid", level: config.LogLevelDebug, want: config.LogLevelDebug, wantSource: config.SourceOverride},
+		{name: "mixed case", level: "ERROR", want: config.LogLevelError, wantSource: config.SourceOverride},
+		{name: "numeric", level: "2", want: config.LogLevelWarn, wantSource: config.SourceOverride},
+		{name: "invalid", level: "verbose", want: config.LogLevelInfo, wantSource: config.SourceEnv, wantErr: true},
+		{name: "empty", level: "", want: config.LogLevelInfo, wantSource: config.SourceEnv, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(map[string]string{config.EnvLogLevel: "info"})
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			err = cfg.SetLogLevel(tt.level)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("SetLogLevel(%q) error = %v, want an error %t", tt.level, err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.HasPrefix(err.Error(), "invalid override: ") {
+				t.Errorf("SetLogLevel(%q) error = %v, want an invalid override error", tt.level, err)
+			}
+			if tt.wantErr && !hasFieldError(err, config.EnvLogLevel) {
+				t.Errorf("SetLogLevel(%q) error = %v, want a %s field error", tt.level, err, config.EnvLogLevel)
+			}
+			if got := cfg.LogLevel(); got != tt.want {
+				t.Errorf("LogLevel() = %q, want %q", got, tt.want)
+			}
+			if got := cfg.Sources()[config.EnvLogLevel]; got != tt.wantSource {
+				t.Errorf("Sources()[%s] = %q, want %q", config.EnvLogLevel, got, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestConfigSetLogFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  config.LogFormat
+		want    config.LogFormat
+		wantErr bool
+	}{
+		{name: "valid", format: config.LogFormatJSON, want: config.LogFormatJSON},
+		{name: "with whitespace", format: " logfmt ", want: config.LogFormatLogfmt},
+		{name: "invalid", format: "xml", want: config.LogFormatText, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(nil)
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			err = cfg.SetLogFormat(tt.format)
+			if gotErr := err != nil; gotErr != tt.wantErr {
+				t.Fatalf("SetLogFormat(%q) error = %v, want an error %t", tt.format, err, tt.wantErr)
+			}
+			if tt.wantErr && !strings.HasPrefix(err.Error(), "invalid override: ") {
+				t.Errorf("SetLogFormat(%q) error = %v, want an invalid override error", tt.format, err)
+			}
+			if got := cfg.LogFormat(); got != tt.want {
+				t.Errorf("LogFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
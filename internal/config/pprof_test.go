@@ -0,0 +1,24 @@
+package config_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mega/internal/config"
+)
+
+func TestDefaultServeMuxServesNoPprof(t *testing.T) {
+	cfg, err := config.LoadFromMap(map[string]string{config.EnvServerPprof: "true"})
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	if !cfg.PprofEnabled() {
+		t.Fatal("PprofEnabled() = false, want true")
+	}
+	rec := httptest.NewRecorder()
+	http.DefaultServeMux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("GET /debug/pprof/ on http.DefaultServeMux: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
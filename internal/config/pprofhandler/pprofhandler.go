@@ -0,0 +1,59 @@
+// Package pprofhandler serves the runtime profiling data of [net/http/pprof] as
+// configured by a [config.Config].
+//
+// It is kept apart from package config because importing [net/http/pprof]
+// registers its handlers on [http.DefaultServeMux], which must only happen in the
+// applications opting into profiling. Importing this package does so as well, so
+// the applications serving [http.DefaultServeMux] should not import it unless they
+// mean to expose the profiles there.
+package pprofhandler
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"strings"
+
+	"mega/internal/config"
+)
+
+const (
+	// standardPathPrefix defines the URL path prefix under which the handlers of
+	// [net/http/pprof] resolve the profiles.
+	standardPathPrefix = "/debug/pprof"
+)
+
+// New returns an [http.Handler] serving the runtime profiling data under
+// [config.Config.PprofPathPrefix], or nil if it is disabled, see
+// [config.Config.PprofEnabled]. The index of the profiles is served at the prefix
+// itself, and each profile below it (e.g., "/debug/pprof/heap").
+//
+// The handler expects the requests to keep the prefix, so it is typically
+// registered as:
+//
+//	mux.Handle(cfg.PprofPathPrefix()+"/", pprofhandler.New(cfg))
+//
+// The profiles disclose the internals of the application, so the handler must not
+// be reachable publicly, see [config.EnvServerPprof].
+func New(cfg *config.Config) http.Handler {
+	if !cfg.PprofEnabled() {
+		return nil
+	}
+	// The handlers of net/http/pprof resolve the profiles from the paths under
+	// their standard prefix, to which the requests are rewritten.
+	mux := http.NewServeMux()
+	mux.HandleFunc(standardPathPrefix+"/", pprof.Index)
+	mux.HandleFunc(standardPathPrefix+"/cmdline", pprof.Cmdline)
+	mux.HandleFunc(standardPathPrefix+"/profile", pprof.Profile)
+	mux.HandleFunc(standardPathPrefix+"/symbol", pprof.Symbol)
+	mux.HandleFunc(standardPathPrefix+"/trace", pprof.Trace)
+	prefix := strings.TrimSuffix(cfg.PprofPathPrefix(), "/")
+	return http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "" {
+			http.Redirect(w, r, prefix+"/", http.StatusMovedPermanently)
+			return
+		}
+		r.URL.Path = standardPathPrefix + r.URL.Path
+		r.URL.RawPath = ""
+		mux.ServeHTTP(w, r)
+	}))
+}
@@ -0,0 +1,91 @@
+package pprofhandler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mega/internal/config"
+	"mega/internal/config/pprofhandler"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name       string
+		env        map[string]string
+		wantNil    bool
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:    "disabled by default",
+			wantNil: true,
+		},
+		{
+			name:    "disabled",
+			env:     map[string]string{config.EnvServerPprof: "false"},
+			wantNil: true,
+		},
+		{
+			name:       "index",
+			env:        map[string]string{config.EnvServerPprof: "true"},
+			path:       "/debug/pprof/",
+			wantStatus: http.StatusOK,
+			wantBody:   "Types of profiles available",
+		},
+		{
+			name:       "profile",
+			env:        map[string]string{config.EnvServerPprof: "true"},
+			path:       "/debug/pprof/goroutine?debug=1",
+			wantStatus: http.StatusOK,
+			wantBody:   "goroutine profile",
+		},
+		{
+			name: "index under custom prefix",
+			env: map[string]string{
+				config.EnvServerPprof:           "true",
+				config.EnvServerPprofPathPrefix: "/ops/pprof",
+			},
+			path:       "/ops/pprof/",
+			wantStatus: http.StatusOK,
+			wantBody:   "Types of profiles available",
+		},
+		{
+			name: "prefix without trailing slash",
+			env: map[string]string{
+				config.EnvServerPprof:           "true",
+				config.EnvServerPprofPathPrefix: "/ops/pprof",
+			},
+			path:       "/ops/pprof",
+			wantStatus: http.StatusMovedPermanently,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(tt.env)
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			h := pprofhandler.New(cfg)
+			if tt.wantNil {
+				if h != nil {
+					t.Fatal("New() != nil, want nil")
+				}
+				return
+			}
+			if h == nil {
+				t.Fatal("New() = nil")
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"sync/atomic"
+)
+
+type (
+	// Reloadable holds a [Config] that can be reloaded from the environment variables
+	// while being read concurrently.
+	Reloadable struct {
+		config atomic.Pointer[Config]
+		opts   []Option
+		logger *slog.Logger
+	}
+)
+
+// NewReloadable creates and returns a new [Reloadable] holding the [Config] loaded
+// by [New] with the given options, which are reused on every reload.
+func NewReloadable(opts ...Option) (*Reloadable, error) {
+	cfg, err := New(opts...)
+	if err != nil {
+		return nil, err
+	}
+	logger := newOptions(opts).reloadLogger
+	if logger == nil {
+		logger = slog.New(slog.DiscardHandler)
+	}
+	r := &Reloadable{
+		opts:   opts,
+		logger: logger,
+	}
+	r.config.Store(cfg)
+	return r, nil
+}
+
+// Load returns the current [Config].
+func (r *Reloadable) Load() *Config {
+	return r.config.Load()
+}
+
+// Reload loads the configuration again and, if valid, replaces the current
+// [Config] with it. Otherwise, the current [Config] is kept and the error is
+// returned.
+//
+// Each reload is logged with the logger configured with [WithReloadLogger], if
+// any: at the info level along with the settings it changed, or at the warn level
+// along with the error if it failed.
+func (r *Reloadable) Reload() error {
+	return r.reload(context.Background())
+}
+
+// reload reloads the configuration as [Reloadable.Reload] does, logging the reload
+// along with attrs.
+func (r *Reloadable) reload(ctx context.Context, attrs ...any) error {
+	cfg, err := New(r.opts...)
+	if err != nil {
+		r.logger.WarnContext(ctx, "failed to reload configuration", append(attrs, "error", err)...)
+		return err
+	}
+	prev := r.config.Swap(cfg)
+	if diff := prev.Diff(cfg); len(diff) > 0 {
+		r.logger.InfoContext(ctx, "reloaded configuration", append(attrs, diffAttrs(diff)...)...)
+	} else {
+		r.logger.InfoContext(ctx, "reloaded configuration with no changes", attrs...)
+	}
+	return nil
+}
+
+// WatchSignals reloads the configuration each time one of the given signals, or
+// SIGHUP when none is given, is received, until ctx is done. On platforms without
+// SIGHUP, at least one signal must be given.
+//
+// Each reload is logged as by [Reloadable.Reload], along with the signal received.
+// A failed reload does not stop the watch.
+//
+// WatchSignals returns ctx.Err() once ctx is done, after unregistering the signal
+// handler.
+func (r *Reloadable) WatchSignals(ctx context.Context, sigs ...os.Signal) error {
+	if len(sigs) == 0 {
+		sigs = defaultReloadSignals
+	}
+	if len(sigs) == 0 {
+		return errors.New("failed to watch signals: no signal given")
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	defer signal.Stop(ch)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case sig := <-ch:
+			// A failed reload is logged, and the current configuration kept.
+			r.reload(ctx, slog.String("signal", sig.String()))
+		}
+	}
+}
+
+// diffAttrs returns the settings changed by a reload, as returned by [Config.Diff],
+// as log attributes sorted by key.
+func diffAttrs(diff map[string]FieldChange) []any {
+	attrs := make([]any, 0, len(diff))
+	for _, key := range slices.Sorted(maps.Keys(diff)) {
+		attrs = append(attrs, slog.String(key, diff[key].String()))
+	}
+	return attrs
+}
@@ -0,0 +1,13 @@
+//go:build js || wasip1 || plan9
+
+package config
+
+import (
+	"os"
+)
+
+var (
+	// defaultReloadSignals defines the signals watched by [Reloadable.WatchSignals]
+	// when none is given, of which there are none on platforms without SIGHUP.
+	defaultReloadSignals []os.Signal
+)
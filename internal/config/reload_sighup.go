@@ -0,0 +1,14 @@
+//go:build !js && !wasip1 && !plan9
+
+package config
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	// defaultReloadSignals defines the signals watched by [Reloadable.WatchSignals]
+	// when none is given.
+	defaultReloadSignals = []os.Signal{syscall.SIGHUP}
+)
@@ -0,0 +1,121 @@
+package config_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"mega/internal/config"
+)
+
+// recordHandler is a [slog.Handler] recording the log records it handles.
+type recordHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *recordHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.records = append(h.records, r)
+	return nil
+}
+
+func (h *recordHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return h
+}
+
+func (h *recordHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// Records returns a copy of the records handled so far.
+func (h *recordHandler) Records() []slog.Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]slog.Record(nil), h.records...)
+}
+
+// recordAttrs returns the attributes of r keyed by name, rendered as strings.
+func recordAttrs(r slog.Record) map[string]string {
+	attrs := make(map[string]string)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs[a.Key] = a.Value.String()
+		return true
+	})
+	return attrs
+}
+
+func TestReloadableReloadLogger(t *testing.T) {
+	t.Setenv(config.EnvLogLevel, "info")
+	h := &recordHandler{}
+	r, err := config.NewReloadable(config.WithReloadLogger(slog.New(h)))
+	if err != nil {
+		t.Fatalf("NewReloadable() error = %v", err)
+	}
+
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	t.Setenv(config.EnvLogLevel, "debug")
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	t.Setenv(config.EnvLogLevel, "bogus")
+	if err := r.Reload(); err == nil {
+		t.Fatal("Reload() error = nil, want an invalid log level")
+	}
+	if got := r.Load().LogLevel(); got != config.LogLevelDebug {
+		t.Errorf("Load().LogLevel() = %q, want the last valid %q", got, config.LogLevelDebug)
+	}
+
+	records := h.Records()
+	if len(records) != 3 {
+		t.Fatalf("logged %d records, want 3", len(records))
+	}
+	want := []struct {
+		level   slog.Level
+		message string
+		attrs   map[string]string
+	}{
+		{slog.LevelInfo, "reloaded configuration with no changes", map[string]string{}},
+		{slog.LevelInfo, "reloaded configuration", map[string]string{config.EnvLogLevel: "info -> debug"}},
+		{slog.LevelWarn, "failed to reload configuration", nil},
+	}
+	for i, w := range want {
+		got := records[i]
+		if got.Level != w.level || got.Message != w.message {
+			t.Errorf("record %d = %s %q, want %s %q", i, got.Level, got.Message, w.level, w.message)
+		}
+		attrs := recordAttrs(got)
+		if w.attrs == nil {
+			if attrs["error"] == "" {
+				t.Errorf("record %d has no error attribute", i)
+			}
+			continue
+		}
+		if len(attrs) != len(w.attrs) {
+			t.Errorf("record %d attributes = %v, want %v", i, attrs, w.attrs)
+		}
+		for key, val := range w.attrs {
+			if attrs[key] != val {
+				t.Errorf("record %d attribute %s = %q, want %q", i, key, attrs[key], val)
+			}
+		}
+	}
+}
+
+func TestReloadableReloadWithoutLogger(t *testing.T) {
+	r, err := config.NewReloadable()
+	if err != nil {
+		t.Fatalf("NewReloadable() error = %v", err)
+	}
+	if err := r.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+//go:build unix
+
+package config_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"mega/internal/config"
+)
+
+func TestReloadableWatchSignals(t *testing.T) {
+	h := &recordHandler{}
+	r, err := config.NewReloadable(config.WithReloadLogger(slog.New(h)))
+	if err != nil {
+		t.Fatalf("NewReloadable() error = %v", err)
+	}
+	// SIGUSR1 terminates the process unless notified, as it may be before
+	// WatchSignals registers its handler.
+	sink := make(chan os.Signal, 1)
+	signal.Notify(sink, syscall.SIGUSR1)
+	defer signal.Stop(sink)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- r.WatchSignals(ctx, syscall.SIGUSR1)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(h.Records()) == 0 && time.Now().Before(deadline) {
+		// The signal is sent until the handler is registered and reloads.
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("WatchSignals() error = %v, want %v", err, context.Canceled)
+	}
+	records := h.Records()
+	if len(records) == 0 {
+		t.Fatal("logged no record")
+	}
+	if got := recordAttrs(records[0])["signal"]; got != syscall.SIGUSR1.String() {
+		t.Errorf("signal attribute = %q, want %q", got, syscall.SIGUSR1.String())
+	}
+}
+
+func TestReloadableWatchSignalsReloads(t *testing.T) {
+	tests := []struct {
+		name string
+		sigs []os.Signal
+		send syscall.Signal
+	}{
+		{name: "default signals", send: syscall.SIGHUP},
+		{name: "given signals", sigs: []os.Signal{syscall.SIGUSR1}, send: syscall.SIGUSR1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(config.EnvLogLevel, "info")
+			r, err := config.NewReloadable()
+			if err != nil {
+				t.Fatalf("NewReloadable() error = %v", err)
+			}
+			old := r.Load()
+			t.Setenv(config.EnvLogLevel, "debug")
+			// The signals terminate the process unless notified, as they may be
+			// before WatchSignals registers its handler.
+			sink := make(chan os.Signal, 1)
+			signal.Notify(sink, tt.send)
+			defer signal.Stop(sink)
+			ctx, cancel := context.WithCancel(context.Background())
+			done := make(chan error)
+			go func() {
+				done <- r.WatchSignals(ctx, tt.sigs...)
+			}()
+			deadline := time.Now().Add(5 * time.Second)
+			for r.Load() == old && time.Now().Before(deadline) {
+				syscall.Kill(syscall.Getpid(), tt.send)
+				time.Sleep(10 * time.Millisecond)
+			}
+			cancel()
+			if err := <-done; err != context.Canceled {
+				t.Errorf("WatchSignals() error = %v, want %v", err, context.Canceled)
+			}
+			cfg := r.Load()
+			if cfg == old {
+				t.Fatal("Load() returned the configuration loaded before the signal")
+			}
+			if got := cfg.LogLevel(); got != config.LogLevelDebug {
+				t.Errorf("LogLevel() = %q, want %q", got, config.LogLevelDebug)
+			}
+			if got := old.LogLevel(); got != config.LogLevelInfo {
+				t.Errorf("LogLevel() of the previous configuration = %q, want %q", got, config.LogLevelInfo)
+			}
+		})
+	}
+}
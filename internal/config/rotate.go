@@ -0,0 +1,161 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	megabyte = 1 << 20
+	day      = 24 * time.Hour
+
+	// rotatingFileBackupLayout defines the time layout appended to the name of
+	// rotated files, chosen so that lexical order matches chronological order.
+	rotatingFileBackupLayout = "20060102T150405.000000000"
+)
+
+type (
+	// rotatingFileLimits represents the limits of a rotating file, where zero values
+	// disable the corresponding limit.
+	rotatingFileLimits struct {
+		maxSize    int64
+		maxBackups int
+		maxAge     time.Duration
+	}
+
+	// rotatingFile represents a file that is renamed to a timestamped backup and
+	// reopened empty once writing to it would exceed its maximum size.
+	rotatingFile struct {
+		mu     sync.Mutex
+		path   string
+		limits rotatingFileLimits
+		file   *os.File
+		size   int64
+	}
+)
+
+func openRotatingFile(path string, limits rotatingFileLimits) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:   path,
+		limits: limits,
+	}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		// The file could not be reopened by the last rotation.
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	if r.limits.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.limits.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file = f
+	r.size = info.Size()
+	return nil
+}
+
+// rotate renames the file to a timestamped backup and reopens it empty. If the
+// file cannot be renamed, it is reopened in place, and if it cannot be reopened,
+// the next write reopens it, so that a transient failure does not prevent the
+// later writes.
+func (r *rotatingFile) rotate() error {
+	backup := r.path + "." + time.Now().UTC().Format(rotatingFileBackupLayout)
+	renameErr := os.Rename(r.path, backup)
+	closeErr := r.file.Close()
+	r.file = nil
+	if renameErr != nil {
+		// Some platforms (e.g., Windows) refuse to rename open files, so the rename
+		// is retried once the file is closed.
+		renameErr = os.Rename(r.path, backup)
+	}
+	if err := r.open(); err != nil {
+		return errors.Join(renameErr, closeErr, err)
+	}
+	if renameErr != nil {
+		return errors.Join(renameErr, closeErr)
+	}
+	return errors.Join(closeErr, r.prune())
+}
+
+// prune removes the backups exceeding the maximum number of backups or the maximum
+// age, oldest first.
+func (r *rotatingFile) prune() error {
+	if r.limits.maxBackups == 0 && r.limits.maxAge == 0 {
+		return nil
+	}
+	dir, base := filepath.Split(r.path)
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	var backups []string
+	for _, entry := range entries {
+		suffix, ok := strings.CutPrefix(entry.Name(), base+".")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if _, err := time.Parse(rotatingFileBackupLayout, suffix); err == nil {
+			backups = append(backups, entry.Name())
+		}
+	}
+	slices.Sort(backups)
+	slices.Reverse(backups)
+	cutoff := time.Now().Add(-r.limits.maxAge)
+	var errs []error
+	for i, name := range backups {
+		path := filepath.Join(dir, name)
+		expired := r.limits.maxBackups > 0 && i >= r.limits.maxBackups
+		if !expired && r.limits.maxAge > 0 {
+			info, err := os.Stat(path)
+			expired = err == nil && info.ModTime().Before(cutoff)
+		}
+		if expired {
+			errs = append(errs, os.Remove(path))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,114 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// backups returns the names of the backups of the rotating file at path.
+func backups(t *testing.T, path string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestRotatingFile(t *testing.T) {
+	tests := []struct {
+		name        string
+		limits      rotatingFileLimits
+		writes      []string
+		wantContent string
+		wantBackups int
+	}{
+		{
+			name:        "unlimited",
+			writes:      []string{"aaaa\n", "bbbb\n", "cccc\n"},
+			wantContent: "aaaa\nbbbb\ncccc\n",
+		},
+		{
+			name:        "rotated",
+			limits:      rotatingFileLimits{maxSize: 8},
+			writes:      []string{"aaaa\n", "bbbb\n", "cccc\n"},
+			wantContent: "cccc\n",
+			wantBackups: 2,
+		},
+		{
+			name:        "pruned",
+			limits:      rotatingFileLimits{maxSize: 8, maxBackups: 1},
+			writes:      []string{"aaaa\n", "bbbb\n", "cccc\n"},
+			wantContent: "cccc\n",
+			wantBackups: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			f, err := openRotatingFile(path, tt.limits)
+			if err != nil {
+				t.Fatalf("openRotatingFile() error = %v", err)
+			}
+			for _, w := range tt.writes {
+				if _, err := f.Write([]byte(w)); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := f.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.wantContent {
+				t.Errorf("content = %q, want %q", data, tt.wantContent)
+			}
+			if got := len(backups(t, path)); got != tt.wantBackups {
+				t.Errorf("backups = %d, want %d", got, tt.wantBackups)
+			}
+		})
+	}
+}
+
+func TestRotatingFileRecovers(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "app.log")
+	f, err := openRotatingFile(path, rotatingFileLimits{maxSize: 8})
+	if err != nil {
+		t.Fatalf("openRotatingFile() error = %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte("aaaa\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	// The rotation fails while the directory is missing.
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("bbbb\n")); err == nil {
+		t.Fatal("Write() error = nil, want the rotation to fail")
+	}
+
+	// The writes succeed again once the directory is back.
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Write([]byte("cccc\n")); err != nil {
+		t.Fatalf("Write() after recovery error = %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "cccc\n") {
+		t.Errorf("content = %q, want it to contain the last write", data)
+	}
+}
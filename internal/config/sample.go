@@ -0,0 +1,105 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+type (
+	// samplingHandler is a [slog.Handler] sampling the records below
+	// [slog.LevelWarn] before passing them to the wrapped handler.
+	samplingHandler struct {
+		slog.Handler
+		sampler *sampler
+	}
+
+	// sampler counts the records with the same level and message per second, and
+	// picks the fraction of them passed at random, shared by a samplingHandler and
+	// the handlers derived from it.
+	sampler struct {
+		initial    int
+		thereafter int
+		rate       float64
+		mu         sync.Mutex
+		window     time.Time
+		counts     map[samplingKey]int
+		rand       *rand.Rand
+	}
+
+	samplingKey struct {
+		level slog.Level
+		msg   string
+	}
+)
+
+// newSamplingHandler creates and returns a new samplingHandler wrapping h, passing
+// the first initial records per second with the same level and message, then
+// every thereafter-th, or none if thereafter is 0, or all of them if initial is 0.
+// Of those, the fraction rate is passed, picked by a pseudo-random generator
+// seeded for the handler.
+func newSamplingHandler(h slog.Handler, initial, thereafter int, rate float64) *samplingHandler {
+	return &samplingHandler{
+		Handler: h,
+		sampler: &sampler{
+			initial:    initial,
+			thereafter: thereafter,
+			rate:       rate,
+			counts:     make(map[samplingKey]int),
+			rand:       rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+		},
+	}
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level < slog.LevelWarn && !h.sampler.sample(r) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{
+		Handler: h.Handler.WithAttrs(attrs),
+		sampler: h.sampler,
+	}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{
+		Handler: h.Handler.WithGroup(name),
+		sampler: h.sampler,
+	}
+}
+
+// sample returns whether r is passed.
+func (s *sampler) sample(r slog.Record) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.initial > 0 && !s.count(r) {
+		return false
+	}
+	return s.rate >= 1 || s.rand.Float64() < s.rate
+}
+
+// count counts r within its second and returns whether it is passed.
+func (s *sampler) count(r slog.Record) bool {
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
+	window := t.Truncate(time.Second)
+	if !window.Equal(s.window) {
+		s.window = window
+		clear(s.counts)
+	}
+	key := samplingKey{level: r.Level, msg: r.Message}
+	s.counts[key]++
+	n := s.counts[key]
+	if n <= s.initial {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.initial)%s.thereafter == 0
+}
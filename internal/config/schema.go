@@ -0,0 +1,104 @@
+package config
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+const (
+	// schemaDraft defines the JSON Schema dialect of [ConfigJSONSchema].
+	schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+	// schemaDurationPattern defines the pattern of [time.Duration] strings, or of
+	// integers in the default duration unit.
+	schemaDurationPattern = `^([0-9]+|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+)$`
+
+	// schemaServerAddressPattern defines the pattern of [EnvServerAddress] values,
+	// either "host:port", "unix://<path>", or "auto".
+	schemaServerAddressPattern = `^(unix://.+|.*:[0-9]+|[aA][uU][tT][oO])$`
+
+	// schemaMetricsAddressPattern defines the pattern of [EnvMetricsAddress] values,
+	// "host:port", or empty to disable metrics.
+	schemaMetricsAddressPattern = `^(.*:[0-9]+)?$`
+
+	// schemaURLPathPattern defines the pattern of URL paths (e.g., [EnvMetricsPath]
+	// values).
+	schemaURLPathPattern = `^/\S*$`
+
+	// schemaSizePattern defines the pattern of sizes, optionally with a unit.
+	schemaSizePattern = `^[0-9]+ *([kKmMgG]i?[bB]|[bB])?$`
+)
+
+// ConfigJSONSchema returns a JSON Schema describing the configuration files read by
+// [NewFromFile], for editors to validate and complete them, typically referenced
+// by their "$schema" key.
+//
+// The schema describes an object whose properties are the lowercase names of the
+// environment variables, with their descriptions, defaults, and accepted values,
+// derived from [EnvSpecs], [AllLogLevels], and [AllLogFormats].
+func ConfigJSONSchema() ([]byte, error) {
+	properties := make(map[string]any)
+	for _, spec := range EnvSpecs() {
+		property := schemaProperty(spec)
+		property["description"] = spec.Description
+		if spec.Default != "" {
+			property["default"] = spec.Default
+		}
+		properties[strings.ToLower(spec.Name)] = property
+	}
+	properties["$schema"] = map[string]any{"type": "string"}
+	return json.MarshalIndent(map[string]any{
+		"$schema":              schemaDraft,
+		"title":                "Application configuration",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}, "", "  ")
+}
+
+// schemaProperty returns the schema of the values accepted by the environment
+// variable specified by spec.
+func schemaProperty(spec EnvSpec) map[string]any {
+	switch spec.Name {
+	case EnvLogLevel:
+		return map[string]any{"type": "string", "enum": enumStrings(AllLogLevels())}
+	case EnvLogFormat, EnvLogSecondaryFormat:
+		return map[string]any{"type": "string", "enum": enumStrings(AllLogFormats())}
+	case EnvServerAddress:
+		return map[string]any{"type": "string", "pattern": schemaServerAddressPattern}
+	case EnvMetricsAddress:
+		return map[string]any{"type": "string", "pattern": schemaMetricsAddressPattern}
+	case EnvMetricsPath, EnvServerPprofPathPrefix:
+		return map[string]any{"type": "string", "pattern": schemaURLPathPattern}
+	case EnvServerPort:
+		return map[string]any{"type": []string{"integer", "string"}, "minimum": TCPPortMin, "maximum": TCPPortMax}
+	case EnvServerMaxHeaderBytes:
+		return map[string]any{"type": []string{"integer", "string"}, "minimum": 1, "pattern": schemaSizePattern}
+	case EnvLogFileMaxSizeMB, EnvLogFileMaxBackups, EnvLogFileMaxAgeDays,
+		EnvLogSampleInitial, EnvLogSampleThereafter, EnvServerMaxConns:
+		return map[string]any{"type": []string{"integer", "string"}, "minimum": 0, "pattern": "^[0-9]+$"}
+	case EnvLogSampleRate:
+		return map[string]any{"type": []string{"number", "string"}, "minimum": 0, "maximum": 1}
+	case EnvServerReadTimeout, EnvServerReadHeaderTimeout, EnvServerWriteTimeout,
+		EnvServerIdleTimeout, EnvServerRequestTimeout, EnvServerShutdownTimeout,
+		EnvServerShutdownGrace:
+		return map[string]any{"type": []string{"integer", "string"}, "minimum": 0, "pattern": schemaDurationPattern}
+	}
+	if len(spec.AllowedValues) > 0 {
+		if slices.Equal(spec.AllowedValues, slices.Concat(boolTrueValues, boolFalseValues)) {
+			return map[string]any{"type": []string{"boolean", "string"}, "enum": append([]any{true, false}, anySlice(spec.AllowedValues)...)}
+		}
+		return map[string]any{"type": "string", "enum": spec.AllowedValues}
+	}
+	return map[string]any{"type": "string"}
+}
+
+// anySlice returns vals as a slice of any.
+func anySlice[T any](vals []T) []any {
+	anys := make([]any, len(vals))
+	for i, val := range vals {
+		anys[i] = val
+	}
+	return anys
+}
@@ -0,0 +1,115 @@
+package config_test
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"mega/internal/config"
+)
+
+// schema is the part of the JSON Schema returned by [config.ConfigJSONSchema]
+// checked by the tests.
+type schema struct {
+	Schema     string `json:"$schema"`
+	Type       string `json:"type"`
+	Properties map[string]struct {
+		Enum    []any  `json:"enum"`
+		Pattern string `json:"pattern"`
+	} `json:"properties"`
+}
+
+func TestConfigJSONSchema(t *testing.T) {
+	data, err := config.ConfigJSONSchema()
+	if err != nil {
+		t.Fatalf("ConfigJSONSchema() error = %v", err)
+	}
+	if !json.Valid(data) {
+		t.Fatalf("ConfigJSONSchema() = %s, want valid JSON", data)
+	}
+	var s schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if s.Schema == "" || s.Type != "object" {
+		t.Errorf("$schema = %q and type = %q, want a JSON Schema of an object", s.Schema, s.Type)
+	}
+	for _, spec := range config.EnvSpecs() {
+		property, ok := s.Properties[strings.ToLower(spec.Name)]
+		if !ok {
+			t.Errorf("properties lack %q", strings.ToLower(spec.Name))
+			continue
+		}
+		if _, err := regexp.Compile(property.Pattern); err != nil {
+			t.Errorf("%s pattern %q error = %v", strings.ToLower(spec.Name), property.Pattern, err)
+		}
+	}
+	enums := []struct {
+		property string
+		want     []string
+	}{
+		{property: "log_level", want: []string{"trace", "debug", "info", "warn", "error"}},
+		{property: "log_format", want: []string{"text", "json", "logfmt"}},
+	}
+	for _, e := range enums {
+		var got []string
+		for _, v := range s.Properties[e.property].Enum {
+			got = append(got, v.(string))
+		}
+		if !slices.Equal(got, e.want) {
+			t.Errorf("%s enum = %q, want %q", e.property, got, e.want)
+		}
+	}
+}
+
+func TestConfigJSONSchemaPatterns(t *testing.T) {
+	data, err := config.ConfigJSONSchema()
+	if err != nil {
+		t.Fatalf("ConfigJSONSchema() error = %v", err)
+	}
+	var s schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	tests := []struct {
+		property string
+		valid    []string
+		invalid  []string
+	}{
+		{
+			property: "server_address",
+			valid:    []string{"localhost:8080", ":80", "unix:///run/app.sock", "auto"},
+			invalid:  []string{"localhost", "unix://"},
+		},
+		{
+			property: "server_read_timeout",
+			valid:    []string{"5s", "1m30s", "250ms", "1.5h", "10"},
+			invalid:  []string{"5 seconds", "-1s", "s"},
+		},
+		{
+			property: "metrics_path",
+			valid:    []string{"/metrics", "/"},
+			invalid:  []string{"metrics", "/with space"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.property, func(t *testing.T) {
+			re, err := regexp.Compile(s.Properties[tt.property].Pattern)
+			if err != nil {
+				t.Fatalf("pattern %q error = %v", s.Properties[tt.property].Pattern, err)
+			}
+			for _, v := range tt.valid {
+				if !re.MatchString(v) {
+					t.Errorf("pattern %q rejects %q", re, v)
+				}
+			}
+			for _, v := range tt.invalid {
+				if re.MatchString(v) {
+					t.Errorf("pattern %q accepts %q", re, v)
+				}
+			}
+		})
+	}
+}
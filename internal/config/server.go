@@ -0,0 +1,214 @@
+package config
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	// requestTimeoutBody defines the body of the responses to the requests timed out
+	// by [Config.RequestTimeoutMiddleware].
+	requestTimeoutBody = "request timed out"
+
+	// accessLogMessage defines the message of the records logged by
+	// [Config.AccessLogMiddleware].
+	accessLogMessage = "request handled"
+)
+
+type (
+	// statusRecorder is an [http.ResponseWriter] recording the status code of the
+	// response written.
+	statusRecorder struct {
+		http.ResponseWriter
+		status int
+	}
+)
+
+// HTTPServer creates and returns a new [http.Server] serving the given handler,
+// configured with the server's address, timeouts, maximum header bytes, and
+// whether HTTP/2 and keep-alives are enabled.
+//
+// The server is not started. For [ServerNetworkUnix], the address is the path of
+// the socket, so the server must be started on a listener created with
+// [Config.ServerNetwork] rather than with [http.Server.ListenAndServe], as done
+// by [Config.Serve].
+func (c *Config) HTTPServer(handler http.Handler) *http.Server {
+	srv := &http.Server{
+		Handler: handler,
+	}
+	c.Apply(srv)
+	return srv
+}
+
+// Apply configures srv, typically built by a framework, as [Config.HTTPServer]
+// does:
+//
+//   - its Addr, ReadTimeout, ReadHeaderTimeout, WriteTimeout, and IdleTimeout
+//     fields, owned by the configuration, are always overwritten
+//   - its MaxHeaderBytes field is set only if it is 0
+//   - its TLSNextProto field is set only if it is nil and HTTP/2 is disabled
+//   - its keep-alives are disabled if they are disabled by the configuration, but
+//     never enabled, so that a server with keep-alives disabled keeps them so
+//
+// Every other field of srv, such as Handler and TLSConfig, is left unchanged.
+func (c *Config) Apply(srv *http.Server) {
+	srv.Addr = c.serverAddress
+	srv.ReadTimeout = c.serverReadTimeout
+	srv.ReadHeaderTimeout = c.serverReadHeaderTimeout
+	srv.WriteTimeout = c.serverWriteTimeout
+	srv.IdleTimeout = c.serverIdleTimeout
+	if srv.MaxHeaderBytes == 0 {
+		srv.MaxHeaderBytes = c.serverMaxHeaderBytes
+	}
+	if !c.serverHTTP2 && srv.TLSNextProto == nil {
+		// A non-nil empty map disables the automatic HTTP/2 support.
+		srv.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	}
+	if !c.serverKeepAlive {
+		srv.SetKeepAlivesEnabled(false)
+	}
+}
+
+// RequestTimeoutMiddleware wraps next with [http.TimeoutHandler], responding with
+// 503 Service Unavailable to the requests not processed within the configured
+// server's request timeout.
+//
+// If the timeout is 0 (none), next is returned unchanged.
+func (c *Config) RequestTimeoutMiddleware(next http.Handler) http.Handler {
+	if c.serverRequestTimeout == 0 {
+		return next
+	}
+	return http.TimeoutHandler(next, c.serverRequestTimeout, requestTimeoutBody)
+}
+
+// AccessLogMiddleware wraps next, logging each request handled with
+// [slog.Default], at [slog.LevelInfo], along with its method, path, response
+// status code, duration, and remote address.
+//
+// If the access log is disabled, see [Config.ServerAccessLog], next is returned
+// unchanged.
+func (c *Config) AccessLogMiddleware(next http.Handler) http.Handler {
+	if !c.serverAccessLog {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		slog.LogAttrs(r.Context(), slog.LevelInfo, accessLogMessage,
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("remote_addr", r.RemoteAddr),
+		)
+	})
+}
+
+// Run serves requests with handler, wrapped by [Config.RequestTimeoutMiddleware]
+// and [Config.AccessLogMiddleware], on a server created by [Config.HTTPServer],
+// until ctx is done, then shuts the server down gracefully, as done by
+// [Config.Serve].
+//
+// Run returns nil once the server is shut down gracefully, or the error that
+// stopped it otherwise.
+func (c *Config) Run(ctx context.Context, handler http.Handler) error {
+	srv := c.HTTPServer(c.AccessLogMiddleware(c.RequestTimeoutMiddleware(handler)))
+	if err := c.Serve(ctx, srv); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Listen listens on the server's address using the configured server's network,
+// recording the address listened on, see [Config.ResolvedAddress].
+//
+// The listener is not limited by [Config.LimitListener], which may wrap it.
+func (c *Config) Listen() (net.Listener, error) {
+	return c.listen(c.serverAddress)
+}
+
+// listen listens on addr using the configured server's network, recording the
+// address listened on.
+func (c *Config) listen(addr string) (net.Listener, error) {
+	ln, err := net.Listen(c.serverNetwork, addr)
+	if err != nil {
+		return nil, err
+	}
+	resolved := ln.Addr().String()
+	c.resolvedAddress.Store(&resolved)
+	return ln, nil
+}
+
+// Serve listens on the address of srv, or on the configured server's address if
+// srv has none, using the configured server's network, limited by
+// [Config.LimitListener], and serves requests with srv, over TLS when
+// [Config.TLSEnabled], until ctx is done, then shuts the server down gracefully,
+// waiting at most the configured server's shutdown timeout for active connections
+// to finish.
+//
+// Once ctx is done, Serve calls the function configured with [WithOnShutdown], if
+// any, and keeps serving requests for the configured server's shutdown grace
+// period before shutting the server down, giving load balancers time to stop
+// routing requests to it.
+//
+// Serve returns [http.ErrServerClosed] once the server is shut down gracefully,
+// the shutdown error if it is not, or the error that stopped the server before
+// ctx was done.
+func (c *Config) Serve(ctx context.Context, srv *http.Server) error {
+	addr := srv.Addr
+	if addr == "" {
+		addr = c.serverAddress
+	}
+	ln, err := c.listen(addr)
+	if err != nil {
+		return err
+	}
+	ln = c.LimitListener(ln)
+	errCh := make(chan error, 1)
+	go func() {
+		if c.TLSEnabled() {
+			errCh <- srv.ServeTLS(ln, c.serverTLSCertFile, c.serverTLSKeyFile)
+			return
+		}
+		errCh <- srv.Serve(ln)
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	if c.onShutdown != nil {
+		c.onShutdown()
+	}
+	if c.serverShutdownGrace > 0 {
+		grace := time.NewTimer(c.serverShutdownGrace)
+		defer grace.Stop()
+		select {
+		case err := <-errCh:
+			return err
+		case <-grace.C:
+		}
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.serverShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return err
+	}
+	return <-errCh
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap returns the wrapped [http.ResponseWriter], for [http.ResponseController].
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,400 @@
+package config_test
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"mega/internal/config"
+)
+
+// freeAddress returns a local TCP address free to listen on.
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+// serve runs cfg.Serve with srv until the server listens, returning the function
+// stopping it and the error Serve returned.
+func serve(t *testing.T, cfg *config.Config, srv *http.Server) func() error {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- cfg.Serve(ctx, srv)
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for cfg.ResolvedAddress() == "" {
+		select {
+		case err := <-done:
+			cancel()
+			t.Fatalf("Serve() error = %v", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			cancel()
+			t.Fatal("Serve() did not listen in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	return func() error {
+		cancel()
+		return <-done
+	}
+}
+
+func TestConfigServe(t *testing.T) {
+	configured := freeAddress(t)
+	explicit := freeAddress(t)
+	tests := []struct {
+		name     string
+		srvAddr  string
+		wantAddr string
+	}{
+		{
+			name:     "server without address",
+			wantAddr: configured,
+		},
+		{
+			name:     "server with address",
+			srvAddr:  explicit,
+			wantAddr: explicit,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(map[string]string{config.EnvServerAddress: configured})
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			srv := &http.Server{
+				Addr: tt.srvAddr,
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+					io.WriteString(w, "ok")
+				}),
+			}
+			stop := serve(t, cfg, srv)
+			if got := cfg.ResolvedAddress(); got != tt.wantAddr {
+				t.Errorf("ResolvedAddress() = %q, want %q", got, tt.wantAddr)
+			}
+			resp, err := http.Get("http://" + tt.wantAddr)
+			if err != nil {
+				t.Fatalf("GET error = %v", err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if string(body) != "ok" {
+				t.Errorf("body = %q, want %q", body, "ok")
+			}
+			if err := stop(); !errors.Is(err, http.ErrServerClosed) {
+				t.Errorf("Serve() error = %v, want %v", err, http.ErrServerClosed)
+			}
+		})
+	}
+}
+
+func TestConfigHTTPServerProtocols(t *testing.T) {
+	tests := []struct {
+		name          string
+		env           map[string]string
+		wantHTTP2     bool
+		wantKeepAlive bool
+	}{
+		{
+			name:          "defaults",
+			env:           map[string]string{},
+			wantHTTP2:     true,
+			wantKeepAlive: true,
+		},
+		{
+			name: "enabled",
+			env: map[string]string{
+				config.EnvServerHTTP2:     "true",
+				config.EnvServerKeepAlive: "true",
+			},
+			wantHTTP2:     true,
+			wantKeepAlive: true,
+		},
+		{
+			name: "disabled",
+			env: map[string]string{
+				config.EnvServerHTTP2:     "false",
+				config.EnvServerKeepAlive: "false",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(tt.env)
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			if got := cfg.ServerHTTP2(); got != tt.wantHTTP2 {
+				t.Errorf("ServerHTTP2() = %t, want %t", got, tt.wantHTTP2)
+			}
+			if got := cfg.ServerKeepAlive(); got != tt.wantKeepAlive {
+				t.Errorf("ServerKeepAlive() = %t, want %t", got, tt.wantKeepAlive)
+			}
+			srv := cfg.HTTPServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			// A nil TLSNextProto enables the automatic HTTP/2 support, and an empty
+			// non-nil one disables it.
+			if gotHTTP2 := srv.TLSNextProto == nil; gotHTTP2 != tt.wantHTTP2 {
+				t.Errorf("TLSNextProto = %v, want HTTP/2 enabled %t", srv.TLSNextProto, tt.wantHTTP2)
+			}
+			ln, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			go srv.Serve(ln)
+			defer srv.Close()
+			resp, err := http.Get("http://" + ln.Addr().String())
+			if err != nil {
+				t.Fatalf("GET error = %v", err)
+			}
+			resp.Body.Close()
+			if gotKeepAlive := !resp.Close; gotKeepAlive != tt.wantKeepAlive {
+				t.Errorf("response keeps the connection alive = %t, want %t", gotKeepAlive, tt.wantKeepAlive)
+			}
+		})
+	}
+}
+
+func TestConfigApply(t *testing.T) {
+	env := map[string]string{
+		config.EnvServerAddress:           "localhost:9000",
+		config.EnvServerReadTimeout:       "1s",
+		config.EnvServerReadHeaderTimeout: "2s",
+		config.EnvServerWriteTimeout:      "3s",
+		config.EnvServerIdleTimeout:       "4s",
+		config.EnvServerMaxHeaderBytes:    "4096",
+		config.EnvServerHTTP2:             "false",
+	}
+	nextProto := map[string]func(*http.Server, *tls.Conn, http.Handler){
+		"custom": func(*http.Server, *tls.Conn, http.Handler) {},
+	}
+	handler := http.NewServeMux()
+	tests := []struct {
+		name               string
+		srv                *http.Server
+		wantMaxHeaderBytes int
+		wantNextProtos     int
+	}{
+		{
+			name:               "empty server",
+			srv:                &http.Server{},
+			wantMaxHeaderBytes: 4096,
+			wantNextProtos:     0,
+		},
+		{
+			name: "pre-populated server",
+			srv: &http.Server{
+				Addr:              ":1",
+				Handler:           handler,
+				ReadTimeout:       time.Minute,
+				ReadHeaderTimeout: time.Minute,
+				WriteTimeout:      time.Minute,
+				IdleTimeout:       time.Minute,
+				MaxHeaderBytes:    1024,
+				TLSNextProto:      nextProto,
+			},
+			wantMaxHeaderBytes: 1024,
+			wantNextProtos:     1,
+		},
+	}
+	cfg, err := config.LoadFromMap(env)
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerBefore := tt.srv.Handler
+			cfg.Apply(tt.srv)
+			if tt.srv.Addr != "localhost:9000" {
+				t.Errorf("Addr = %q, want %q", tt.srv.Addr, "localhost:9000")
+			}
+			timeouts := []struct {
+				name      string
+				got, want time.Duration
+			}{
+				{"ReadTimeout", tt.srv.ReadTimeout, time.Second},
+				{"ReadHeaderTimeout", tt.srv.ReadHeaderTimeout, 2 * time.Second},
+				{"WriteTimeout", tt.srv.WriteTimeout, 3 * time.Second},
+				{"IdleTimeout", tt.srv.IdleTimeout, 4 * time.Second},
+			}
+			for _, timeout := range timeouts {
+				if timeout.got != timeout.want {
+					t.Errorf("%s = %s, want %s", timeout.name, timeout.got, timeout.want)
+				}
+			}
+			if tt.srv.MaxHeaderBytes != tt.wantMaxHeaderBytes {
+				t.Errorf("MaxHeaderBytes = %d, want %d", tt.srv.MaxHeaderBytes, tt.wantMaxHeaderBytes)
+			}
+			if tt.srv.TLSNextProto == nil || len(tt.srv.TLSNextProto) != tt.wantNextProtos {
+				t.Errorf("TLSNextProto = %v, want %d entries", tt.srv.TLSNextProto, tt.wantNextProtos)
+			}
+			if tt.srv.Handler != handlerBefore {
+				t.Error("Handler changed")
+			}
+		})
+	}
+}
+
+func TestConfigListen(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		wantHost string
+	}{
+		{name: "ephemeral port", address: "127.0.0.1:0", wantHost: "127.0.0.1"},
+		{name: "auto", address: "auto"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(map[string]string{config.EnvServerAddress: tt.address})
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			if got := cfg.ResolvedAddress(); got != "" {
+				t.Errorf("ResolvedAddress() before Listen = %q, want empty", got)
+			}
+			ln, err := cfg.Listen()
+			if err != nil {
+				t.Fatalf("Listen() error = %v", err)
+			}
+			defer ln.Close()
+			resolved := cfg.ResolvedAddress()
+			host, port, err := net.SplitHostPort(resolved)
+			if err != nil {
+				t.Fatalf("ResolvedAddress() = %q, want a host:port address", resolved)
+			}
+			if port == "" || port == "0" {
+				t.Errorf("ResolvedAddress() = %q, want a non-zero port", resolved)
+			}
+			if tt.wantHost != "" && host != tt.wantHost {
+				t.Errorf("ResolvedAddress() = %q, want host %q", resolved, tt.wantHost)
+			}
+			if resolved != ln.Addr().String() {
+				t.Errorf("ResolvedAddress() = %q, want the listener address %q", resolved, ln.Addr())
+			}
+		})
+	}
+}
+
+func TestConfigCloneResolvedAddress(t *testing.T) {
+	cfg, err := config.LoadFromMap(map[string]string{config.EnvServerAddress: "127.0.0.1:0"})
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 100 {
+			cfg.Clone().ResolvedAddress()
+		}
+	}()
+	ln, err := cfg.Listen()
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+	<-done
+	clone := cfg.Clone()
+	if got, want := clone.ResolvedAddress(), cfg.ResolvedAddress(); got != want {
+		t.Errorf("Clone().ResolvedAddress() = %q, want %q", got, want)
+	}
+	cloneLn, err := clone.Listen()
+	if err != nil {
+		t.Fatalf("Clone().Listen() error = %v", err)
+	}
+	defer cloneLn.Close()
+	if got, want := cfg.ResolvedAddress(), ln.Addr().String(); got != want {
+		t.Errorf("ResolvedAddress() after Clone().Listen() = %q, want %q", got, want)
+	}
+}
+
+func TestConfigRun(t *testing.T) {
+	tests := []struct {
+		name  string
+		grace string
+	}{
+		{name: "without grace period", grace: "0s"},
+		{name: "with grace period", grace: "100ms"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shutdown := make(chan struct{})
+			cfg, err := config.LoadFromMap(map[string]string{
+				config.EnvServerAddress:         "127.0.0.1:0",
+				config.EnvServerAccessLog:       "false",
+				config.EnvServerShutdownGrace:   tt.grace,
+				config.EnvServerShutdownTimeout: "5s",
+			}, config.WithOnShutdown(func() { close(shutdown) }))
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			started := make(chan struct{})
+			release := make(chan struct{})
+			handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				close(started)
+				<-release
+				io.WriteString(w, "done")
+			})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan error, 1)
+			go func() {
+				done <- cfg.Run(ctx, handler)
+			}()
+			deadline := time.Now().Add(5 * time.Second)
+			for cfg.ResolvedAddress() == "" && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			type response struct {
+				body string
+				err  error
+			}
+			responses := make(chan response, 1)
+			go func() {
+				resp, err := http.Get("http://" + cfg.ResolvedAddress())
+				if err != nil {
+					responses <- response{err: err}
+					return
+				}
+				defer resp.Body.Close()
+				body, err := io.ReadAll(resp.Body)
+				responses <- response{body: string(body), err: err}
+			}()
+			<-started
+			// The in-flight request is completed before the server shuts down.
+			cancel()
+			<-shutdown
+			select {
+			case err := <-done:
+				t.Fatalf("Run() returned %v with a request in flight", err)
+			case <-time.After(50 * time.Millisecond):
+			}
+			close(release)
+			if resp := <-responses; resp.err != nil || resp.body != "done" {
+				t.Errorf("GET = %q, %v, want %q", resp.body, resp.err, "done")
+			}
+			select {
+			case err := <-done:
+				if err != nil {
+					t.Errorf("Run() error = %v, want nil", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("Run() did not return once shut down")
+			}
+		})
+	}
+}
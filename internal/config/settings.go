@@ -0,0 +1,342 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type (
+	// FieldChange represents the change of a setting between two [Config] values,
+	// rendered as the value of the environment variable configuring it.
+	FieldChange struct {
+		Old string
+		New string
+	}
+
+	// setting represents a configured value rendered as the value of the
+	// environment variable configuring it.
+	setting struct {
+		key       string
+		value     string
+		sensitive bool
+	}
+
+	// bannerRow represents a row of [Config.Banner], showing the setting configured
+	// by the environment variable named by key.
+	bannerRow struct {
+		key      string
+		duration bool
+	}
+)
+
+const (
+	// redactedValue defines the value rendered in place of sensitive settings when
+	// redacted.
+	redactedValue = "****"
+
+	// bannerTitle defines the title of [Config.Banner].
+	bannerTitle = "Configuration"
+)
+
+var (
+	// bannerRows defines the rows of [Config.Banner], in order.
+	bannerRows = []bannerRow{
+		{EnvServerAddress, false},
+		{EnvLogLevel, false},
+		{EnvLogFormat, false},
+		{EnvLogOutput, false},
+		{EnvServerReadTimeout, true},
+		{EnvServerReadHeaderTimeout, true},
+		{EnvServerWriteTimeout, true},
+		{EnvServerIdleTimeout, true},
+		{EnvServerRequestTimeout, true},
+		{EnvServerShutdownTimeout, true},
+	}
+)
+
+// WriteTo writes the configuration to w as "KEY=value" lines, one per setting,
+// keyed by the environment variable configuring it, with the values aligned and in
+// a stable order. The values of the sensitive settings are redacted, see
+// [WithRedactedKeys].
+//
+// WriteTo implements [io.WriterTo].
+func (c *Config) WriteTo(w io.Writer) (int64, error) {
+	settings := c.redactedSettings()
+	width := 0
+	for _, s := range settings {
+		width = max(width, len(s.key))
+	}
+	var total int64
+	for _, s := range settings {
+		line := strings.TrimRight(fmt.Sprintf("%-*s%s", width+1, s.key+"=", s.value), " ")
+		n, err := io.WriteString(w, line+"\n")
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Environ returns the configuration as "KEY=VALUE" entries, in the format of
+// [os.Environ], one per setting keyed by the environment variable configuring it,
+// in a stable order. Loading them back with [LoadFromMap] yields an equal
+// configuration, so they may be used to reproduce it.
+//
+// Empty settings, standing for unset optional values, are left out. If redact is
+// true, the values of the sensitive settings (e.g., [EnvServerTLSKeyFile], and
+// those configured with [WithRedactedKeys]) are replaced, in which case the entries
+// no longer reproduce the configuration.
+func (c *Config) Environ(redact bool) []string {
+	settings := c.settings()
+	if redact {
+		settings = c.redactedSettings()
+	}
+	var environ []string
+	for _, s := range settings {
+		if s.value != "" {
+			environ = append(environ, s.key+"="+s.value)
+		}
+	}
+	return environ
+}
+
+// ToMap returns the configuration as a map, with one entry per setting keyed by the
+// environment variable configuring it, for in-process consumption (e.g., by
+// templates or debug endpoints). Unlike [Config.Environ], the values keep their
+// types: the durations are [time.Duration] values, the log level and format are
+// [LogLevel] and [LogFormat] values, and so on, while the lists are copies.
+//
+// If redact is true, the values of the sensitive settings, if set, are replaced by
+// the "****" string, see [WithRedactedKeys].
+func (c *Config) ToMap(redact bool) map[string]any {
+	m := make(map[string]any)
+	for _, f := range fields {
+		if f.value == nil {
+			continue
+		}
+		var val any = f.value(c)
+		if redact && val != "" && (f.sensitive || slices.Contains(c.redactedKeys, f.spec.Name)) {
+			val = redactedValue
+		} else if f.typed != nil {
+			val = f.typed(c)
+		}
+		m[f.spec.Name] = val
+	}
+	return m
+}
+
+// String returns the configuration formatted as by [Config.WriteTo], with the
+// values of the sensitive settings redacted, and the credentials embedded in the
+// addresses and log outputs (e.g., the userinfo of URLs) masked.
+func (c *Config) String() string {
+	var b strings.Builder
+	c.WriteTo(&b)
+	return b.String()
+}
+
+// Banner returns a summary of the key settings (e.g., the server address, the log
+// level, format, and output, and the server timeouts) framed in a box, one per
+// line, meant to be written once as the application starts. Unlike
+// [Config.String], it is formatted for human eyes rather than for parsing: the
+// durations are shortened (e.g., "1m" rather than "1m0s"), and disabled timeouts
+// are rendered as "none". The values of the sensitive settings are redacted, see
+// [WithRedactedKeys], and the embedded credentials masked, as by [Config.String].
+func (c *Config) Banner() string {
+	values := make(map[string]string)
+	for _, s := range c.redactedSettings() {
+		values[s.key] = s.value
+	}
+	labels := make(map[string]string)
+	labelWidth := 0
+	for _, row := range bannerRows {
+		f, _ := lookupField(row.key)
+		labels[row.key] = f.label
+		labelWidth = max(labelWidth, len(f.label))
+	}
+	lines := []string{bannerTitle}
+	for _, row := range bannerRows {
+		value := values[row.key]
+		if d, err := time.ParseDuration(value); err == nil && row.duration {
+			value = formatDuration(d)
+		}
+		lines = append(lines, fmt.Sprintf("%-*s  %s", labelWidth, labels[row.key], value))
+	}
+	width := 0
+	for _, line := range lines {
+		width = max(width, utf8.RuneCountInString(line))
+	}
+	border := "+" + strings.Repeat("-", width+2) + "+\n"
+	var b strings.Builder
+	for i, line := range lines {
+		if i <= 1 {
+			b.WriteString(border)
+		}
+		fmt.Fprintf(&b, "| %-*s |\n", width, line)
+	}
+	b.WriteString(border)
+	return b.String()
+}
+
+// formatDuration returns d formatted as by [time.Duration.String], without the
+// trailing zero units (e.g., "1m" rather than "1m0s"), or "none" if d is 0.
+func formatDuration(d time.Duration) string {
+	if d == 0 {
+		return "none"
+	}
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// MarshalJSON implements [json.Marshaler], encoding the configuration as an object
+// in the format read by [NewFromFile], keyed by the lowercase names of the
+// environment variables, with the values of the sensitive settings redacted. Empty
+// settings, standing for unset optional values, are left out.
+func (c *Config) MarshalJSON() ([]byte, error) {
+	values := make(map[string]string)
+	for _, s := range c.redactedSettings() {
+		if s.value != "" {
+			values[strings.ToLower(s.key)] = s.value
+		}
+	}
+	return json.Marshal(values)
+}
+
+// redactedSettings returns the settings of the configuration, with the values of
+// the sensitive ones, if set, redacted, and the credentials embedded in the others
+// masked, see sanitizeForDisplay.
+func (c *Config) redactedSettings() []setting {
+	settings := c.settings()
+	for i, s := range settings {
+		if s.value != "" && (s.sensitive || slices.Contains(c.redactedKeys, s.key)) {
+			settings[i].value = redactedValue
+		} else {
+			settings[i].value = sanitizeForDisplay(s.key, s.value)
+		}
+	}
+	return settings
+}
+
+// sanitizeForDisplay returns value, the setting configured by the environment
+// variable named by field, with the credentials it may embed masked, if its field
+// is marked as such: the userinfo of URLs (e.g., "unix://user:token@/run/app.sock"
+// is rendered as "unix://****@/run/app.sock") and the values of query parameters.
+// The values of lists are sanitized element by element, and the plain addresses
+// (e.g., "localhost:8080") are returned unchanged.
+func sanitizeForDisplay(field, value string) string {
+	if f, ok := lookupField(field); !ok || !f.credentials {
+		return value
+	}
+	elems := strings.Split(value, logOutputSeparator)
+	for i, elem := range elems {
+		if scheme, rest, ok := strings.Cut(elem, "://"); ok {
+			// The userinfo ends at the last "@" of the authority, which may hold
+			// percent-encoded or unencoded "@" characters.
+			authority, _, _ := strings.Cut(rest, "/")
+			if at := strings.LastIndex(authority, "@"); at != -1 {
+				rest = redactedValue + rest[at:]
+			}
+			elem = scheme + "://" + rest
+		}
+		if base, query, ok := strings.Cut(elem, "?"); ok {
+			params := strings.Split(query, "&")
+			for j, param := range params {
+				if key, _, ok := strings.Cut(param, "="); ok {
+					params[j] = key + "=" + redactedValue
+				}
+			}
+			elem = base + "?" + strings.Join(params, "&")
+		}
+		elems[i] = elem
+	}
+	return strings.Join(elems, logOutputSeparator)
+}
+
+// String returns the change formatted as "<old> -> <new>".
+func (fc FieldChange) String() string {
+	return fc.Old + " -> " + fc.New
+}
+
+// Diff returns the settings that differ between c and other, keyed by the
+// environment variable configuring them, or an empty map when there are none. The
+// values of the sensitive settings are redacted, see [WithRedactedKeys].
+func (c *Config) Diff(other *Config) map[string]FieldChange {
+	diff := make(map[string]FieldChange)
+	settings, redactedSettings := c.settings(), c.redactedSettings()
+	otherSettings, otherRedactedSettings := other.settings(), other.redactedSettings()
+	for i, s := range settings {
+		if s.value != otherSettings[i].value {
+			diff[s.key] = FieldChange{
+				Old: redactedSettings[i].value,
+				New: otherRedactedSettings[i].value,
+			}
+		}
+	}
+	return diff
+}
+
+// DiffString returns the settings that differ between c and other, as computed by
+// [Config.Diff], formatted as "KEY: old -> new" lines in the order of [EnvSpecs],
+// or an empty string when there are none. It eases reporting mismatches, as in
+// tests comparing a configuration against the expected one.
+func (c *Config) DiffString(other *Config) string {
+	diff := c.Diff(other)
+	var b strings.Builder
+	for _, s := range c.settings() {
+		if change, ok := diff[s.key]; ok {
+			fmt.Fprintf(&b, "%s: %s\n", s.key, change)
+		}
+	}
+	return b.String()
+}
+
+// env returns the settings keyed by the environment variable configuring them, such
+// that loading them back yields the same configuration. Empty settings are left out
+// when they have no default value, as they stand for unset optional values, and
+// kept otherwise, so that loading them back reports them instead of falling back
+// to the default.
+func (c *Config) env() map[string]string {
+	defaults := make(map[string]string)
+	for _, spec := range EnvSpecs() {
+		defaults[spec.Name] = spec.Default
+	}
+	env := make(map[string]string)
+	for _, s := range c.settings() {
+		if s.value != "" || defaults[s.key] != "" {
+			env[s.key] = s.value
+		}
+	}
+	return env
+}
+
+// settings returns the configured values in the order of [EnvSpecs], leaving out
+// the environment variables that only contribute to the setting of another.
+func (c *Config) settings() []setting {
+	settings := make([]setting, 0, len(fields))
+	for _, f := range fields {
+		if f.value != nil {
+			settings = append(settings, setting{f.spec.Name, f.value(c), f.sensitive})
+		}
+	}
+	return settings
+}
+
+// joinStrings returns the string representations of vals joined by sep.
+func joinStrings[T fmt.Stringer](vals []T, sep string) string {
+	strs := make([]string, len(vals))
+	for i, val := range vals {
+		strs[i] = val.String()
+	}
+	return strings.Join(strs, sep)
+}
@@ -0,0 +1,424 @@
+package config_test
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"mega/internal/config"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// golden compares got with the content of the golden file testdata/name, which is
+// rewritten instead when the tests are run with -update.
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output mismatch with %s\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+var errWrite = errors.New("write failed")
+
+// limitWriter is an [io.Writer] buffering up to limit bytes, failing with errWrite
+// past them.
+type limitWriter struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if room := w.limit - w.buf.Len(); len(p) > room {
+		n, _ := w.buf.Write(p[:room])
+		return n, errWrite
+	}
+	return w.buf.Write(p)
+}
+
+func TestConfigWriteTo(t *testing.T) {
+	cfg, err := config.LoadFromMap(map[string]string{
+		config.EnvLogLevel:      "debug",
+		config.EnvLogFormat:     "json",
+		config.EnvServerAddress: "0.0.0.0:9090",
+	})
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		limit   int
+		wantErr error
+	}{
+		{name: "complete", limit: 1 << 20},
+		{name: "failing writer", limit: 100, wantErr: errWrite},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &limitWriter{limit: tt.limit}
+			n, err := cfg.WriteTo(w)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("WriteTo() error = %v, want %v", err, tt.wantErr)
+			}
+			if n != int64(w.buf.Len()) {
+				t.Errorf("WriteTo() = %d, want the %d bytes written", n, w.buf.Len())
+			}
+			if tt.wantErr == nil {
+				golden(t, "writeto.golden", w.buf.Bytes())
+			}
+		})
+	}
+}
+
+// environMap returns the "KEY=VALUE" entries of environ keyed by name.
+func environMap(environ []string) map[string]string {
+	env := make(map[string]string, len(environ))
+	for _, entry := range environ {
+		key, val, _ := strings.Cut(entry, "=")
+		env[key] = val
+	}
+	return env
+}
+
+func TestConfigEnvironRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	for _, path := range []string{certFile, keyFile} {
+		if err := os.WriteFile(path, nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name string
+		env  map[string]string
+	}{
+		{
+			name: "defaults",
+			env:  map[string]string{},
+		},
+		{
+			name: "every setting",
+			env: map[string]string{
+				config.EnvLogLevel:              "debug",
+				config.EnvLogLevelsByName:       "db=warn,http=error",
+				config.EnvLogFormat:             "logfmt",
+				config.EnvLogOutput:             "stderr," + filepath.Join(dir, "app.log"),
+				config.EnvLogSecondaryOutput:    "stdout",
+				config.EnvLogSecondaryFormat:    "json",
+				config.EnvLogFileMaxSizeMB:      "10",
+				config.EnvLogFileMaxBackups:     "2",
+				config.EnvLogFileMaxAgeDays:     "7",
+				config.EnvLogAddSource:          "true",
+				config.EnvLogTimeFormat:         "rfc3339",
+				config.EnvLogTimeUTC:            "true",
+				config.EnvLogColor:              "never",
+				config.EnvLogSyslogTag:          "app",
+				config.EnvLogSampleInitial:      "5",
+				config.EnvLogSampleThereafter:   "10",
+				config.EnvLogSampleRate:         "0.5",
+				config.EnvLogDefaultAttrs:       "service=api,region=eu",
+				config.EnvServerAddress:         "0.0.0.0:9090",
+				config.EnvServerReadTimeout:     "1m30s",
+				config.EnvServerWriteTimeout:    "2m",
+				config.EnvServerIdleTimeout:     "3m",
+				config.EnvServerRequestTimeout:  "250ms",
+				config.EnvServerShutdownTimeout: "20s",
+				config.EnvServerShutdownGrace:   "3s",
+				config.EnvServerMaxHeaderBytes:  "2048",
+				config.EnvServerMaxConns:        "100",
+				config.EnvServerAccessLog:       "false",
+				config.EnvServerHTTP2:           "false",
+				config.EnvServerKeepAlive:       "false",
+				config.EnvServerPprof:           "true",
+				config.EnvServerPprofPathPrefix: "/internal/pprof",
+				config.EnvServerTrustedProxies:  "10.0.0.0/8,192.168.1.1/32",
+				config.EnvServerTLSCertFile:     certFile,
+				config.EnvServerTLSKeyFile:      keyFile,
+				config.EnvMetricsAddress:        "0.0.0.0:9100",
+				config.EnvMetricsPath:           "/internal/metrics",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(tt.env)
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			environ := cfg.Environ(false)
+			got, err := config.LoadFromMap(environMap(environ))
+			if err != nil {
+				t.Fatalf("LoadFromMap(Environ()) error = %v", err)
+			}
+			if diff := cfg.DiffString(got); diff != "" {
+				t.Errorf("configuration loaded from Environ() differs:\n%s", diff)
+			}
+			if !reflect.DeepEqual(got.ToMap(false), cfg.ToMap(false)) {
+				t.Errorf("ToMap() = %v, want %v", got.ToMap(false), cfg.ToMap(false))
+			}
+			if !slices.Equal(got.Environ(false), environ) {
+				t.Errorf("Environ() = %q, want %q", got.Environ(false), environ)
+			}
+		})
+	}
+}
+
+func TestWithRedactedKeys(t *testing.T) {
+	const secret = "s3cr3t-token"
+	load := func(tag string) *config.Config {
+		cfg, err := config.LoadFromMap(map[string]string{
+			config.EnvLogSyslogTag: tag,
+		}, config.WithRedactedKeys(config.EnvLogSyslogTag))
+		if err != nil {
+			t.Fatalf("LoadFromMap() error = %v", err)
+		}
+		return cfg
+	}
+	cfg := load(secret)
+	other := load("other-" + secret)
+	tests := []struct {
+		name   string
+		key    string
+		render func() string
+	}{
+		{
+			name:   "String",
+			key:    config.EnvLogSyslogTag,
+			render: cfg.String,
+		},
+		{
+			name: "WriteTo",
+			key:  config.EnvLogSyslogTag,
+			render: func() string {
+				var b strings.Builder
+				cfg.WriteTo(&b)
+				return b.String()
+			},
+		},
+		{
+			name: "MarshalJSON",
+			key:  "log_syslog_tag",
+			render: func() string {
+				data, err := cfg.MarshalJSON()
+				if err != nil {
+					t.Fatalf("MarshalJSON() error = %v", err)
+				}
+				return string(data)
+			},
+		},
+		{
+			name: "Environ",
+			key:  config.EnvLogSyslogTag,
+			render: func() string {
+				return strings.Join(cfg.Environ(true), "\n")
+			},
+		},
+		{
+			name: "ToMap",
+			key:  config.EnvLogSyslogTag,
+			render: func() string {
+				return fmt.Sprint(cfg.ToMap(true))
+			},
+		},
+		{
+			name: "Diff",
+			key:  config.EnvLogSyslogTag,
+			render: func() string {
+				return fmt.Sprint(cfg.Diff(other))
+			},
+		},
+		{
+			name: "DiffString",
+			key:  config.EnvLogSyslogTag,
+			render: func() string {
+				return cfg.DiffString(other)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.render()
+			if strings.Contains(got, secret) {
+				t.Errorf("rendered %q, want %s redacted", got, secret)
+			}
+			if !strings.Contains(got, tt.key) || !strings.Contains(got, "****") {
+				t.Errorf("rendered %q, want %s kept with its value masked", got, tt.key)
+			}
+		})
+	}
+	if environ := cfg.Environ(false); !slices.Contains(environ, config.EnvLogSyslogTag+"="+secret) {
+		t.Errorf("Environ(false) = %q, want %s unredacted", environ, config.EnvLogSyslogTag)
+	}
+}
+
+func TestConfigDiffString(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		other map[string]string
+		want  string
+	}{
+		{
+			name:  "equal",
+			env:   map[string]string{config.EnvLogLevel: "info"},
+			other: map[string]string{config.EnvLogLevel: "info"},
+			want:  "",
+		},
+		{
+			name:  "one field",
+			env:   map[string]string{config.EnvLogLevel: "info"},
+			other: map[string]string{config.EnvLogLevel: "debug"},
+			want:  "LOG_LEVEL: info -> debug\n",
+		},
+		{
+			name: "several fields in a stable order",
+			env: map[string]string{
+				config.EnvServerWriteTimeout: "10s",
+				config.EnvLogFormat:          "text",
+			},
+			other: map[string]string{
+				config.EnvServerWriteTimeout: "1m",
+				config.EnvLogFormat:          "json",
+			},
+			want: "LOG_FORMAT: text -> json\nSERVER_WRITE_TIMEOUT: 10s -> 1m0s\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(tt.env)
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			other, err := config.LoadFromMap(tt.other)
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			if got := cfg.DiffString(other); got != tt.want {
+				t.Errorf("DiffString() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigBanner(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		opts   []config.Option
+		golden string
+	}{
+		{
+			name:   "defaults",
+			env:    map[string]string{},
+			golden: "banner-defaults.golden",
+		},
+		{
+			name: "custom",
+			env: map[string]string{
+				config.EnvLogLevel:             "debug",
+				config.EnvLogFormat:            "json",
+				config.EnvLogOutput:            "stderr,discard",
+				config.EnvServerReadTimeout:    "90s",
+				config.EnvServerWriteTimeout:   "0",
+				config.EnvServerRequestTimeout: "1h30m",
+			},
+			golden: "banner-custom.golden",
+		},
+		{
+			name:   "redacted",
+			env:    map[string]string{config.EnvServerAddress: "10.0.0.1:8443"},
+			opts:   []config.Option{config.WithRedactedKeys(config.EnvServerAddress)},
+			golden: "banner-redacted.golden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := config.LoadFromMap(tt.env, tt.opts...)
+			if err != nil {
+				t.Fatalf("LoadFromMap() error = %v", err)
+			}
+			golden(t, tt.golden, []byte(cfg.Banner()))
+		})
+	}
+}
+
+func TestConfigToMap(t *testing.T) {
+	cfg, err := config.LoadFromMap(map[string]string{
+		config.EnvLogLevel:          "debug",
+		config.EnvLogSyslogTag:      "secret",
+		config.EnvServerReadTimeout: "3s",
+	}, config.WithRedactedKeys(config.EnvLogSyslogTag))
+	if err != nil {
+		t.Fatalf("LoadFromMap() error = %v", err)
+	}
+	// Every setting is written by String, one per line.
+	var keys []string
+	for line := range strings.Lines(cfg.String()) {
+		key, _, _ := strings.Cut(line, "=")
+		keys = append(keys, key)
+	}
+	tests := []struct {
+		name       string
+		redact     bool
+		wantValues map[string]any
+	}{
+		{
+			name: "typed",
+			wantValues: map[string]any{
+				config.EnvLogLevel:          config.LogLevelDebug,
+				config.EnvLogFormat:         config.LogFormatText,
+				config.EnvLogSyslogTag:      "secret",
+				config.EnvServerReadTimeout: 3 * time.Second,
+				config.EnvServerMaxConns:    0,
+				config.EnvServerHTTP2:       true,
+			},
+		},
+		{
+			name:   "redacted",
+			redact: true,
+			wantValues: map[string]any{
+				config.EnvLogLevel:          config.LogLevelDebug,
+				config.EnvLogSyslogTag:      "****",
+				config.EnvServerReadTimeout: 3 * time.Second,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := cfg.ToMap(tt.redact)
+			if len(m) != len(keys) {
+				t.Errorf("len(ToMap()) = %d, want %d, one per setting", len(m), len(keys))
+			}
+			for _, key := range keys {
+				if _, ok := m[key]; !ok {
+					t.Errorf("ToMap() lacks %s", key)
+				}
+			}
+			for key, want := range tt.wantValues {
+				if got := m[key]; !reflect.DeepEqual(got, want) {
+					t.Errorf("ToMap()[%s] = %#v, want %#v", key, got, want)
+				}
+			}
+		})
+	}
+}
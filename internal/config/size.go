@@ -0,0 +1,49 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+var (
+	// sizeUnits maps the lowercase unit suffixes accepted by [parseSize] to their
+	// size in bytes.
+	sizeUnits = map[string]int64{
+		"":    1,
+		"b":   1,
+		"kb":  1000,
+		"mb":  1000 * 1000,
+		"gb":  1000 * 1000 * 1000,
+		"kib": 1 << 10,
+		"mib": 1 << 20,
+		"gib": 1 << 30,
+	}
+)
+
+// parseSize parses a number of bytes optionally followed by a unit suffix, either
+// decimal ("KB", "MB", "GB") or binary ("KiB", "MiB", "GiB"), case-insensitively
+// (e.g., "512", "64KB", "2GiB").
+func parseSize(s string) (int64, error) {
+	str := strings.TrimSpace(s)
+	i := strings.IndexFunc(str, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if i == -1 {
+		i = len(str)
+	}
+	num, suffix := str[:i], strings.TrimSpace(str[i:])
+	if num == "" {
+		return 0, fmt.Errorf("invalid size %q: missing number of bytes", s)
+	}
+	unit, ok := sizeUnits[strings.ToLower(suffix)]
+	if !ok {
+		return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, suffix)
+	}
+	val, err := strconv.ParseInt(num, 10, 64)
+	if err != nil || val > math.MaxInt64/unit {
+		return 0, fmt.Errorf("invalid size %q: overflows %d bytes", s, int64(math.MaxInt64))
+	}
+	return val * unit, nil
+}
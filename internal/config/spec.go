@@ -0,0 +1,46 @@
+package config
+
+import (
+	"slices"
+)
+
+type (
+	// EnvSpec describes an environment variable supported by the application
+	// configuration.
+	EnvSpec struct {
+		// Name is the name of the environment variable.
+		Name string
+
+		// Default is the value used when the environment variable is unset, or empty
+		// when there is none.
+		Default string
+
+		// Description is a short human-readable description of the environment
+		// variable.
+		Description string
+
+		// AllowedValues lists the values accepted by the environment variable when
+		// restricted to a fixed set, or is nil otherwise.
+		AllowedValues []string
+	}
+)
+
+// EnvSpecs returns the specifications of every environment variable supported by
+// the application configuration, in the order they are documented.
+func EnvSpecs() []EnvSpec {
+	specs := make([]EnvSpec, len(fields))
+	for i, f := range fields {
+		specs[i] = f.spec
+		specs[i].AllowedValues = slices.Clone(f.spec.AllowedValues)
+	}
+	return specs
+}
+
+// enumStrings returns the string representations of the enum values vals.
+func enumStrings[T ~string](vals []T) []string {
+	strs := make([]string, len(vals))
+	for i, val := range vals {
+		strs[i] = string(val)
+	}
+	return strs
+}
@@ -0,0 +1,65 @@
+package config_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+
+	"mega/internal/config"
+)
+
+// envConstants returns the values of the exported Env* constants declared by the
+// package sources, keyed by their name.
+func envConstants(t *testing.T) map[string]string {
+	t.Helper()
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "config.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	consts := make(map[string]string)
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			for i, name := range vs.Names {
+				if !strings.HasPrefix(name.Name, "Env") || i >= len(vs.Values) {
+					continue
+				}
+				lit, ok := vs.Values[i].(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				val, err := strconv.Unquote(lit.Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				consts[name.Name] = val
+			}
+		}
+	}
+	return consts
+}
+
+func TestEnvSpecs(t *testing.T) {
+	specs := config.EnvSpecs()
+	counts := make(map[string]int)
+	for _, spec := range specs {
+		counts[spec.Name]++
+	}
+	consts := envConstants(t)
+	if len(specs) != len(consts) {
+		t.Errorf("len(EnvSpecs()) = %d, want %d, one per Env* constant", len(specs), len(consts))
+	}
+	for name, val := range consts {
+		if counts[val] != 1 {
+			t.Errorf("EnvSpecs() lists %s (%q) %d times, want 1", name, val, counts[val])
+		}
+	}
+}
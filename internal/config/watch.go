@@ -0,0 +1,97 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+const (
+	// watchFilePollInterval defines how often [WatchFile] checks the file for changes.
+	watchFilePollInterval = 100 * time.Millisecond
+
+	// DefaultReloadDebounce defines how long [WatchFile] waits for the file to stop
+	// changing before reloading it, unless configured with [WithReloadDebounce].
+	DefaultReloadDebounce = 200 * time.Millisecond
+)
+
+type (
+	// fileState represents the observable state of a file, compared to detect its
+	// changes.
+	fileState struct {
+		exists  bool
+		size    int64
+		modTime time.Time
+	}
+)
+
+// WatchFile watches the configuration file at path and, each time it changes,
+// reloads it like [NewFromFile], sending the new [Config] on the returned config
+// channel, or the error on the returned error channel when the reloaded
+// configuration is invalid, in which case the last [Config] sent remains the
+// latest valid one. An error identical to the last one sent is not sent again
+// until a reload succeeds, so that saving a file still invalid does not repeat it.
+//
+// Changes are detected by polling the file, and successive changes are debounced
+// so that a single reload follows the multiple writes of a single save, see
+// [WithReloadDebounce].
+//
+// Both channels must be drained, and are closed once ctx is done.
+func WatchFile(ctx context.Context, path string, opts ...Option) (<-chan *Config, <-chan error) {
+	configs := make(chan *Config)
+	errs := make(chan error)
+	go func() {
+		defer close(configs)
+		defer close(errs)
+		poll := time.NewTicker(watchFilePollInterval)
+		defer poll.Stop()
+		wait := newOptions(opts).reloadDebounce()
+		debounce := time.NewTimer(wait)
+		debounce.Stop()
+		defer debounce.Stop()
+		last := statFile(path)
+		var lastErr error
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-poll.C:
+				if state := statFile(path); state != last {
+					last = state
+					debounce.Reset(wait)
+				}
+			case <-debounce.C:
+				cfg, err := NewFromFile(path, opts...)
+				switch {
+				case err == nil:
+					lastErr = nil
+					send(ctx, configs, cfg)
+				case lastErr == nil || err.Error() != lastErr.Error():
+					lastErr = err
+					send(ctx, errs, err)
+				}
+			}
+		}
+	}()
+	return configs, errs
+}
+
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{
+		exists:  true,
+		size:    info.Size(),
+		modTime: info.ModTime(),
+	}
+}
+
+// send sends v on ch unless ctx is done first.
+func send[T any](ctx context.Context, ch chan<- T, v T) {
+	select {
+	case ch <- v:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,80 @@
+package config_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"mega/internal/config"
+)
+
+func TestWatchFileDebounce(t *testing.T) {
+	const debounce = 300 * time.Millisecond
+	tests := []struct {
+		name      string
+		writes    []string
+		wantLevel config.LogLevel
+	}{
+		{
+			name: "burst",
+			writes: []string{
+				`{"log_level": "debug"}`,
+				`{"log_level": "warn", "log_format": "text"}`,
+				`{"log_level": "error", "log_format": "json"}`,
+			},
+			wantLevel: config.LogLevelError,
+		},
+		{
+			name: "burst ending valid",
+			writes: []string{
+				`{"log_level": "`,
+				`{"log_level": "verbose"}`,
+				`{"log_level": "info", "log_format": "logfmt"}`,
+			},
+			wantLevel: config.LogLevelInfo,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, "config.json", `{}`)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			configs, errs := config.WatchFile(ctx, path, config.WithReloadDebounce(debounce))
+			// The watcher must take its initial snapshot of the file first.
+			time.Sleep(50 * time.Millisecond)
+			for _, content := range tt.writes {
+				if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+			var got []*config.Config
+			timeout := time.After(2 * time.Second)
+			for len(got) == 0 {
+				select {
+				case cfg := <-configs:
+					got = append(got, cfg)
+				case err := <-errs:
+					t.Fatalf("WatchFile() sent error %v, want none", err)
+				case <-timeout:
+					t.Fatal("WatchFile() sent no configuration")
+				}
+			}
+			// No other reload follows within the debounce window.
+			select {
+			case cfg := <-configs:
+				got = append(got, cfg)
+			case err := <-errs:
+				t.Fatalf("WatchFile() sent error %v, want none", err)
+			case <-time.After(3 * debounce):
+			}
+			if len(got) != 1 {
+				t.Fatalf("WatchFile() sent %d configurations, want 1", len(got))
+			}
+			if level := got[0].LogLevel(); level != tt.wantLevel {
+				t.Errorf("LogLevel() = %q, want %q", level, tt.wantLevel)
+			}
+		})
+	}
+}